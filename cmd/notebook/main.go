@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	_ "time/tzdata" // Embed zone data so SITE_TIMEZONE works on minimal images
 
 	"notebook.oceanheart.ai/internal/config"
 	"notebook.oceanheart.ai/internal/content"
@@ -20,24 +21,11 @@ func main() {
 	defer db.Close()
 
 	// Load content from filesystem and cache in database
-	loader := content.NewLoader(cfg.ContentDir)
-	posts, err := loader.LoadAll()
-	if err != nil {
-		log.Printf("Warning: Failed to load content: %v", err)
-		posts = []*store.Post{} // Continue with empty posts
-	}
-
-	// Cache posts in database
-	if len(posts) > 0 {
-		if err := db.UpsertPosts(posts); err != nil {
-			log.Printf("Warning: Failed to cache posts: %v", err)
-		} else {
-			log.Printf("Loaded and cached %d posts", len(posts))
-		}
-	}
+	loadErrs := loadContent(cfg, db)
 
 	// Create HTTP server with handlers
 	server := httpserver.NewServer(db, cfg)
+	server.SetLoadErrors(loadErrs)
 
 	// Setup routes
 	mux := http.NewServeMux()
@@ -46,15 +34,21 @@ func main() {
 	mux.HandleFunc("/", server.HomeHandler)
 	mux.HandleFunc("/p/", server.PostHandler)
 	mux.HandleFunc("/tag/", server.TagHandler)
+	mux.HandleFunc("/tags", server.TagsHandler)
+	mux.HandleFunc("/series/", server.SeriesHandler)
+	mux.HandleFunc("/archive", server.ArchiveHandler)
+	mux.HandleFunc("/img/", server.ImageHandler)
 	mux.HandleFunc("/static/", server.StaticHandler)
 	mux.HandleFunc("/static/chroma.css", server.ChromaCSSHandler)
 
 	// SEO and feed routes
 	mux.HandleFunc("/feed.xml", server.FeedHandler)
 	mux.HandleFunc("/sitemap.xml", server.SitemapHandler)
+	mux.HandleFunc("/graph.json", server.GraphHandler)
 
 	// Admin/reload endpoint (dev allowed; prod requires token)
 	mux.HandleFunc("/admin/reload", server.AdminReloadHandler)
+	mux.HandleFunc("/admin/errors", server.ErrorsHandler)
 
 	// Health check endpoint
 	mux.HandleFunc("/healthz", healthCheckHandler)
@@ -81,6 +75,42 @@ func main() {
 	}
 }
 
+// loadContent loads the content directory and caches it in db. Files that
+// fail to load are skipped and returned so /admin/errors can show them; the
+// server starts with whatever loaded.
+func loadContent(cfg *config.Config, db *store.Store) content.LoadErrors {
+	loader := content.NewLoaderFromConfig(cfg)
+	site, err := loader.LoadSite()
+	loadErrs := content.AsLoadErrors(err)
+	if err != nil && loadErrs == nil {
+		log.Printf("Warning: %v", err)
+		db.SetTaxonomy(site.Taxonomy)
+		return nil
+	}
+	for _, le := range loadErrs {
+		if le.Warning {
+			log.Printf("Warning: %v", le)
+		} else {
+			log.Printf("Warning: Skipped content file %v", le)
+		}
+	}
+
+	db.SetTaxonomy(site.Taxonomy)
+	if len(site.Posts) > 0 {
+		if err := db.UpsertPosts(site.Posts); err != nil {
+			log.Printf("Warning: Failed to cache posts: %v", err)
+		} else {
+			log.Printf("Loaded and cached %d posts", len(site.Posts))
+		}
+	}
+	if len(site.Sections) > 0 {
+		if err := db.UpsertSections(site.Sections); err != nil {
+			log.Printf("Warning: Failed to cache sections: %v", err)
+		}
+	}
+	return loadErrs
+}
+
 // healthCheckHandler returns basic health check response
 func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"notebook.oceanheart.ai/internal/config"
+	"notebook.oceanheart.ai/internal/store"
+)
+
+func TestLoadContent(t *testing.T) {
+	contentDir := t.TempDir()
+	write := func(rel, body string) {
+		path := filepath.Join(contentDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", rel, err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", rel, err)
+		}
+	}
+
+	write("taxonomy.yaml", "aliases:\n  golang: go\n")
+	write("2025-09-12-links.md", "---\ntitle: \"Links\"\ndate: \"2025-09-12\"\ntags: [golang]\n---\n\n"+
+		"See [the docs](https://go.dev/doc/), [home](https://example.com/about) and [a post](/p/note).\n\n"+
+		"<img src=x onerror=\"alert(1)\">\n")
+	write("notes/_index.md", "---\ntitle: \"Notes\"\n---\n\nMirrored on [GitHub](https://github.com/example/notes).\n")
+	write("notes/2025-09-13-note.md", "---\ntitle: \"Note\"\ndate: \"2025-09-13\"\n---\n\nBody.\n")
+	write("broken.md", "---\ntitle: \"Broken\"\ndate: \"not a date\"\n---\n\nBody.\n")
+
+	cfg := &config.Config{
+		ContentDir:   contentDir,
+		SiteBaseURL:  "https://example.com",
+		Timezone:     "UTC",
+		SanitizeHTML: true,
+		LinkTarget:   "_blank",
+		LinkRel:      "noopener noreferrer",
+	}
+	db := store.MustOpen(filepath.Join(t.TempDir(), "notebook.db"))
+	defer db.Close()
+
+	loadErrs := loadContent(cfg, db)
+	if len(loadErrs) != 1 || !strings.HasSuffix(loadErrs[0].Path, "broken.md") {
+		t.Errorf("Expected only broken.md reported, got %v", loadErrs)
+	}
+
+	post, err := db.GetPostBySlug("links")
+	if err != nil || post == nil {
+		t.Fatalf("Expected links post cached, got %v, %v", post, err)
+	}
+	if !strings.Contains(post.HTML, `<a href="https://go.dev/doc/" target="_blank" rel="noopener noreferrer">`) {
+		t.Errorf("Expected external link processed, got %s", post.HTML)
+	}
+	if !strings.Contains(post.HTML, `<a href="https://example.com/about">`) || !strings.Contains(post.HTML, `<a href="/p/note">`) {
+		t.Errorf("Expected internal links untouched, got %s", post.HTML)
+	}
+	if strings.Contains(post.HTML, "onerror") {
+		t.Errorf("Expected HTML sanitised, got %s", post.HTML)
+	}
+	if strings.Join(post.Tags, ",") != "go" {
+		t.Errorf("Expected taxonomy aliases applied, got %v", post.Tags)
+	}
+
+	backlinks, err := db.GetBacklinks("note")
+	if err != nil || len(backlinks) != 1 || backlinks[0].Slug != "links" {
+		t.Errorf("Expected the /p/note link stored as a backlink, got %v, %v", backlinks, err)
+	}
+
+	section, err := db.GetSection("notes")
+	if err != nil || section == nil {
+		t.Fatalf("Expected notes section cached, got %v, %v", section, err)
+	}
+	if !strings.Contains(section.IntroHTML, `target="_blank" rel="noopener noreferrer"`) {
+		t.Errorf("Expected section intro links processed, got %s", section.IntroHTML)
+	}
+}
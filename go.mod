@@ -3,14 +3,20 @@ module notebook.oceanheart.ai
 go 1.22.7
 
 require (
-	github.com/alecthomas/chroma/v2 v2.20.0 // indirect
+	github.com/HugoSmits86/nativewebp v0.9.3
+	github.com/alecthomas/chroma/v2 v2.20.0
+	github.com/mattn/go-sqlite3 v1.14.32
+	github.com/tursodatabase/libsql-client-go v0.0.0-20240902231107-85af5b9d094d
+	github.com/yuin/goldmark v1.7.13
+	github.com/yuin/goldmark-highlighting/v2 v2.0.0-20230729083705-37449abec8cc
+	golang.org/x/image v0.20.0
+	golang.org/x/net v0.35.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
 	github.com/antlr4-go/antlr/v4 v4.13.0 // indirect
 	github.com/coder/websocket v1.8.12 // indirect
 	github.com/dlclark/regexp2 v1.11.5 // indirect
-	github.com/mattn/go-sqlite3 v1.14.32 // indirect
-	github.com/tursodatabase/libsql-client-go v0.0.0-20240902231107-85af5b9d094d // indirect
-	github.com/yuin/goldmark v1.7.13 // indirect
-	github.com/yuin/goldmark-highlighting/v2 v2.0.0-20230729083705-37449abec8cc // indirect
 	golang.org/x/exp v0.0.0-20240325151524-a685a6edb6d8 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -1,29 +1,47 @@
 package config
 
 import (
+	"log"
 	"os"
+	"time"
 )
 
 type Config struct {
-    Environment string
-    DBPath      string
-    ContentDir  string
-    SiteBaseURL string
-    SiteTitle   string
-    Port        string
-    ReloadToken string
+    Environment        string
+    DBPath             string
+    ContentDir         string
+    SiteBaseURL        string
+    SiteTitle          string
+    Port               string
+    ReloadToken        string
+    ImageCacheDir      string // Where resized image variants are written
+    Timezone           string // IANA zone for date-only front matter and displayed dates
+    SanitizeHTML       bool   // Strip unsafe HTML from rendered markdown (SANITIZE_HTML=false disables)
+    LinkTarget         string // target added to external links; empty adds none
+    LinkRel            string // rel added to external links; empty adds none
+    HighlightStyle     string // Chroma style for code blocks
+    HighlightStyleDark string // Chroma style under prefers-color-scheme: dark; empty for none
+    CodeCopyButtons    bool   // Add copy buttons to code blocks (CODE_COPY_BUTTONS=false disables)
 }
 
 // LoadConfig loads configuration from environment variables with defaults
 func LoadConfig() *Config {
     return &Config{
-        Environment: getEnv("ENV", "prod"),
-        DBPath:      getEnv("DB_PATH", "./notebook.db"),
-        ContentDir:  getEnv("CONTENT_DIR", "./content"),
-        SiteBaseURL: getEnv("SITE_BASEURL", "https://notebook.oceanheart.ai"),
-        SiteTitle:   getEnv("SITE_TITLE", "Oceanheart Notebook"),
-        Port:        getEnv("PORT", "8003"),
-        ReloadToken: getEnv("RELOAD_TOKEN", ""),
+        Environment:        getEnv("ENV", "prod"),
+        DBPath:             getEnv("DB_PATH", "./notebook.db"),
+        ContentDir:         getEnv("CONTENT_DIR", "./content"),
+        SiteBaseURL:        getEnv("SITE_BASEURL", "https://notebook.oceanheart.ai"),
+        SiteTitle:          getEnv("SITE_TITLE", "Oceanheart Notebook"),
+        Port:               getEnv("PORT", "8003"),
+        ReloadToken:        getEnv("RELOAD_TOKEN", ""),
+        ImageCacheDir:      getEnv("IMAGE_CACHE_DIR", "./.cache/img"),
+        Timezone:           getEnv("SITE_TIMEZONE", "UTC"),
+        SanitizeHTML:       getEnv("SANITIZE_HTML", "true") != "false",
+        LinkTarget:         getOptionalEnv("EXTERNAL_LINK_TARGET", "_blank"),
+        LinkRel:            getOptionalEnv("EXTERNAL_LINK_REL", "noopener noreferrer"),
+        HighlightStyle:     getEnv("HIGHLIGHT_STYLE", "github"),
+        HighlightStyleDark: getOptionalEnv("HIGHLIGHT_STYLE_DARK", "github-dark"),
+        CodeCopyButtons:    getEnv("CODE_COPY_BUTTONS", "true") != "false",
     }
 }
 
@@ -35,11 +53,34 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getOptionalEnv is getEnv for settings that can be switched off: the value
+// "none" yields an empty string
+func getOptionalEnv(key, defaultValue string) string {
+	if value := getEnv(key, defaultValue); value != "none" {
+		return value
+	}
+	return ""
+}
+
 // IsDev returns true if running in development mode
 func (c *Config) IsDev() bool {
 	return c.Environment == "dev"
 }
 
+// Location returns the site's time zone, falling back to UTC when Timezone
+// is empty or not a known IANA zone
+func (c *Config) Location() *time.Location {
+	if c.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		log.Printf("Unknown SITE_TIMEZONE %q, using UTC: %v", c.Timezone, err)
+		return time.UTC
+	}
+	return loc
+}
+
 // IsAdmin returns true if admin endpoints should be enabled
 func (c *Config) IsAdmin() bool {
     return c.IsDev()
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -52,3 +53,56 @@ func TestConfigMethods(t *testing.T) {
 		t.Error("Expected IsAdmin() to return true for dev environment")
 	}
 }
+
+func TestLocation(t *testing.T) {
+	cfg := &Config{}
+	if cfg.Location() != time.UTC {
+		t.Error("Expected UTC when no timezone is configured")
+	}
+
+	cfg = &Config{Timezone: "Not/AZone"}
+	if cfg.Location() != time.UTC {
+		t.Error("Expected UTC fallback for an unknown timezone")
+	}
+
+	cfg = &Config{Timezone: "America/Los_Angeles"}
+	if got := cfg.Location().String(); got != "America/Los_Angeles" {
+		t.Errorf("Expected America/Los_Angeles, got %s", got)
+	}
+}
+
+func TestExternalLinkSettings(t *testing.T) {
+	cfg := LoadConfig()
+	if cfg.LinkTarget != "_blank" || cfg.LinkRel != "noopener noreferrer" {
+		t.Errorf("Unexpected link defaults %q %q", cfg.LinkTarget, cfg.LinkRel)
+	}
+
+	os.Setenv("EXTERNAL_LINK_TARGET", "none")
+	os.Setenv("EXTERNAL_LINK_REL", "nofollow noopener")
+	defer os.Unsetenv("EXTERNAL_LINK_TARGET")
+	defer os.Unsetenv("EXTERNAL_LINK_REL")
+
+	cfg = LoadConfig()
+	if cfg.LinkTarget != "" || cfg.LinkRel != "nofollow noopener" {
+		t.Errorf("Unexpected link settings %q %q", cfg.LinkTarget, cfg.LinkRel)
+	}
+}
+
+func TestHighlightSettings(t *testing.T) {
+	cfg := LoadConfig()
+	if cfg.HighlightStyle != "github" || cfg.HighlightStyleDark != "github-dark" || !cfg.CodeCopyButtons {
+		t.Errorf("Unexpected highlight defaults %q %q %v", cfg.HighlightStyle, cfg.HighlightStyleDark, cfg.CodeCopyButtons)
+	}
+
+	os.Setenv("HIGHLIGHT_STYLE", "monokailight")
+	os.Setenv("HIGHLIGHT_STYLE_DARK", "none")
+	os.Setenv("CODE_COPY_BUTTONS", "false")
+	defer os.Unsetenv("HIGHLIGHT_STYLE")
+	defer os.Unsetenv("HIGHLIGHT_STYLE_DARK")
+	defer os.Unsetenv("CODE_COPY_BUTTONS")
+
+	cfg = LoadConfig()
+	if cfg.HighlightStyle != "monokailight" || cfg.HighlightStyleDark != "" || cfg.CodeCopyButtons {
+		t.Errorf("Unexpected highlight settings %q %q %v", cfg.HighlightStyle, cfg.HighlightStyleDark, cfg.CodeCopyButtons)
+	}
+}
@@ -0,0 +1,74 @@
+package content
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// bundleIndexFile marks a directory as a page bundle: the directory holds the
+// post's markdown plus the images and other files it references
+const bundleIndexFile = "index.md"
+
+// assetBaseKey carries the URL prefix for a bundle's relative references
+var assetBaseKey = parser.NewContextKey()
+
+// bundleAssetTransformer rewrites relative image destinations such as
+// ![](diagram.png) to the URL the bundle's assets are served from
+type bundleAssetTransformer struct{}
+
+// Transform implements parser.ASTTransformer
+func (t *bundleAssetTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	base, _ := pc.Get(assetBaseKey).(string)
+	if base == "" {
+		return
+	}
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if img, ok := n.(*ast.Image); ok {
+			if rewritten, ok := rewriteBundleURL(string(img.Destination), base); ok {
+				img.Destination = []byte(rewritten)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// rewriteBundleURL resolves a relative reference against a bundle's asset base.
+// Absolute URLs, root-relative paths, fragments and references that escape the
+// bundle directory are left alone.
+func rewriteBundleURL(dest, base string) (string, bool) {
+	if dest == "" || strings.HasPrefix(dest, "/") || strings.HasPrefix(dest, "#") {
+		return "", false
+	}
+
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return "", false
+	}
+
+	clean := path.Clean(u.Path)
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", false
+	}
+
+	u.Path = strings.TrimSuffix(base, "/") + "/" + clean
+	return u.String(), true
+}
+
+// BundleAssetPath returns the URL a page bundle's files are served from
+func BundleAssetPath(slug string) string {
+	return "/p/" + slug
+}
+
+// bundleSlug recovers the slug from a path built by BundleAssetPath
+func bundleSlug(assetBase string) string {
+	return strings.TrimPrefix(strings.TrimSuffix(assetBase, "/"), "/p/")
+}
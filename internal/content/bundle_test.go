@@ -0,0 +1,142 @@
+package content
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRewriteBundleURL(t *testing.T) {
+	base := "/p/notebook"
+
+	testCases := []struct {
+		dest     string
+		expected string
+		rewrite  bool
+	}{
+		{"diagram.png", "/p/notebook/diagram.png", true},
+		{"./img/diagram.png", "/p/notebook/img/diagram.png", true},
+		{"diagram.png?v=2#top", "/p/notebook/diagram.png?v=2#top", true},
+		{"/static/logo.png", "", false},
+		{"https://example.com/a.png", "", false},
+		{"//cdn.example.com/a.png", "", false},
+		{"../other/secret.png", "", false},
+		{"#anchor", "", false},
+		{"", "", false},
+	}
+
+	for _, tc := range testCases {
+		result, ok := rewriteBundleURL(tc.dest, base)
+		if ok != tc.rewrite || result != tc.expected {
+			t.Errorf("rewriteBundleURL(%q) = %q, %v; expected %q, %v", tc.dest, result, ok, tc.expected, tc.rewrite)
+		}
+	}
+}
+
+func TestRenderRewritesBundleImages(t *testing.T) {
+	renderer := NewRenderer()
+	markdown := "![Diagram](diagram.png)\n\n![Remote](https://example.com/x.png)\n"
+
+	html, err := renderer.RenderWithOptions(markdown, RenderOptions{AssetBase: "/p/notebook"})
+	if err != nil {
+		t.Fatalf("RenderWithOptions failed: %v", err)
+	}
+	if !strings.Contains(html, `src="/p/notebook/diagram.png"`) {
+		t.Errorf("Expected relative image to be rewritten, got %s", html)
+	}
+	if !strings.Contains(html, `src="https://example.com/x.png"`) {
+		t.Errorf("Expected absolute image to be unchanged, got %s", html)
+	}
+
+	// Without an asset base, references are left alone
+	html, err = renderer.Render(markdown)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(html, `src="diagram.png"`) {
+		t.Errorf("Expected image to be untouched without asset base, got %s", html)
+	}
+}
+
+func TestLoadAllPageBundles(t *testing.T) {
+	tempDir := t.TempDir()
+	bundle := filepath.Join(tempDir, "projects", "2025-09-17-notebook")
+	if err := os.MkdirAll(bundle, 0755); err != nil {
+		t.Fatalf("Failed to create bundle dir: %v", err)
+	}
+
+	index := "---\ntitle: \"Notebook\"\ndate: \"2025-09-17\"\n---\n\n![Architecture](diagram.png)\n"
+	if err := os.WriteFile(filepath.Join(bundle, "index.md"), []byte(index), 0644); err != nil {
+		t.Fatalf("Failed to write index.md: %v", err)
+	}
+	// Markdown siblings inside a bundle are assets, not posts
+	if err := os.WriteFile(filepath.Join(bundle, "notes.md"), []byte("scratch"), 0644); err != nil {
+		t.Fatalf("Failed to write notes.md: %v", err)
+	}
+
+	loader := NewLoader(tempDir, "")
+	posts, err := loader.LoadAll()
+	if err != nil {
+		t.Fatalf("LoadAll failed: %v", err)
+	}
+	if len(posts) != 1 {
+		t.Fatalf("Expected 1 post from the bundle, got %d", len(posts))
+	}
+
+	post := posts[0]
+	if post.Slug != "notebook" {
+		t.Errorf("Expected slug 'notebook', got %s", post.Slug)
+	}
+	if post.Section != "projects" {
+		t.Errorf("Expected section 'projects', got %s", post.Section)
+	}
+	if post.BundleDir != "projects/2025-09-17-notebook" {
+		t.Errorf("Expected bundle dir 'projects/2025-09-17-notebook', got %s", post.BundleDir)
+	}
+	if !strings.Contains(post.HTML, `src="/p/notebook/diagram.png"`) {
+		t.Errorf("Expected image reference to be rewritten, got %s", post.HTML)
+	}
+}
+
+func TestRenderResponsiveBundleImages(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewNRGBA(image.Rect(0, 0, 1200, 600))
+	f, err := os.Create(filepath.Join(dir, "diagram.png"))
+	if err != nil {
+		t.Fatalf("Failed to create image: %v", err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	f.Close()
+
+	renderer := NewRenderer()
+	html, err := renderer.RenderWithOptions("![Diagram](diagram.png)\n\n![Remote](https://example.com/x.png)\n", RenderOptions{
+		AssetBase: "/p/notebook",
+		AssetDir:  dir,
+	})
+	if err != nil {
+		t.Fatalf("RenderWithOptions failed: %v", err)
+	}
+
+	expected := []string{
+		`width="1200"`,
+		`height="600"`,
+		`loading="lazy"`,
+		`/img/notebook/480/diagram.png.webp 480w, /img/notebook/960/diagram.png.webp 960w, /p/notebook/diagram.png 1200w`,
+		`sizes="(max-width: 680px) 100vw, 680px"`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(html, exp) {
+			t.Errorf("Expected HTML to contain %s, got %s", exp, html)
+		}
+	}
+
+	// Remote images are lazy-loaded but get no srcset
+	if strings.Count(html, "srcset=") != 1 || strings.Count(html, `loading="lazy"`) != 2 {
+		t.Errorf("Expected one srcset and two lazy images, got %s", html)
+	}
+}
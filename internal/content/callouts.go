@@ -0,0 +1,246 @@
+package content
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// calloutTypes are the callout kinds GitHub supports, with their default
+// titles and 16x16 stroke icons
+var calloutTypes = map[string]struct {
+	title string
+	icon  string
+}{
+	"note":      {"Note", `<circle cx="8" cy="8" r="6.5" /><path d="M8 7.25v3.5M8 5v.25" />`},
+	"tip":       {"Tip", `<path d="M5.75 11.5c0-1.5-2.25-2.5-2.25-5a4.5 4.5 0 0 1 9 0c0 2.5-2.25 3.5-2.25 5z" /><path d="M6.25 14h3.5" />`},
+	"important": {"Important", `<path d="M2 2.5h12v8.5H7.5L4.5 13.5V11H2z" /><path d="M8 5v2.5M8 9.25v.25" />`},
+	"warning":   {"Warning", `<path d="M8 1.75 14.75 13.75H1.25z" /><path d="M8 6.25v3.25M8 11.5v.25" />`},
+	"caution":   {"Caution", `<path d="M5.25 1.5h5.5l3.75 3.75v5.5l-3.75 3.75h-5.5L1.5 10.75v-5.5z" /><path d="M8 4.75v3.75M8 10.75v.25" />`},
+}
+
+// kindCallout is the AST node kind of a callout
+var kindCallout = ast.NewNodeKind("Callout")
+
+// callout is an admonition such as a note or warning. Its children are the
+// callout's body.
+type callout struct {
+	ast.BaseBlock
+	CalloutType string // Key of calloutTypes
+	Title       string // Custom title; empty uses the type's default
+	fence       int    // Colons opening a ::: container; 0 for a blockquote
+}
+
+// Kind implements ast.Node
+func (n *callout) Kind() ast.NodeKind {
+	return kindCallout
+}
+
+// Dump implements ast.Node
+func (n *callout) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Type": n.CalloutType, "Title": n.Title}, nil)
+}
+
+// callouts is a goldmark extension for GitHub-style alert blockquotes,
+//
+//	> [!NOTE]
+//	> Body text.
+//
+// and fenced containers,
+//
+//	:::warning Optional title
+//	Body text.
+//	:::
+//
+// Both render as <aside class="callout callout-{type}">.
+type callouts struct{}
+
+// Extend implements goldmark.Extender
+func (e *callouts) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithBlockParsers(
+		util.Prioritized(&calloutQuoteParser{}, 790), // Before blockquotes
+		util.Prioritized(&calloutContainerParser{}, 760),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&calloutRenderer{}, 500),
+	))
+}
+
+// parseCalloutHeader splits "type title" after a callout marker, returning
+// ok false for unknown types
+func parseCalloutHeader(typ, title []byte) (string, string, bool) {
+	t := strings.ToLower(string(typ))
+	if _, ok := calloutTypes[t]; !ok {
+		return "", "", false
+	}
+	return t, strings.TrimSpace(string(title)), true
+}
+
+// calloutQuoteParser opens a callout for a blockquote whose first line is
+// [!TYPE], optionally followed by a title. The rest of the quote is parsed
+// by goldmark's own blockquote parser.
+type calloutQuoteParser struct{}
+
+// Trigger implements parser.BlockParser
+func (p *calloutQuoteParser) Trigger() []byte {
+	return []byte{'>'}
+}
+
+// Open implements parser.BlockParser
+func (p *calloutQuoteParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	w, pos := util.IndentWidth(line, reader.LineOffset())
+	if w > 3 || pos >= len(line) || line[pos] != '>' {
+		return nil, parser.NoChildren
+	}
+	marker := util.TrimLeftSpace(line[pos+1:])
+	if !bytes.HasPrefix(marker, []byte("[!")) {
+		return nil, parser.NoChildren
+	}
+	end := bytes.IndexByte(marker, ']')
+	if end < 0 {
+		return nil, parser.NoChildren
+	}
+	typ, title, ok := parseCalloutHeader(marker[2:end], marker[end+1:])
+	if !ok {
+		return nil, parser.NoChildren
+	}
+	advanceLine(reader, line, segment)
+	return &callout{CalloutType: typ, Title: title}, parser.HasChildren
+}
+
+// Continue implements parser.BlockParser
+func (p *calloutQuoteParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	return parser.NewBlockquoteParser().Continue(node, reader, pc)
+}
+
+// Close implements parser.BlockParser
+func (p *calloutQuoteParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser
+func (p *calloutQuoteParser) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser
+func (p *calloutQuoteParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// calloutContainerParser parses :::type containers, closed by a line of at
+// least as many colons. Nest containers by giving the outer one more colons.
+type calloutContainerParser struct{}
+
+// Trigger implements parser.BlockParser
+func (p *calloutContainerParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+// colonFence returns the number of colons line starts with
+func colonFence(line []byte) int {
+	n := 0
+	for n < len(line) && line[n] == ':' {
+		n++
+	}
+	return n
+}
+
+// Open implements parser.BlockParser
+func (p *calloutContainerParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+	fence := colonFence(line[pos:])
+	if fence < 3 {
+		return nil, parser.NoChildren
+	}
+	header := util.TrimLeftSpace(line[pos+fence:])
+	end := 0
+	for end < len(header) && util.IsAlphaNumeric(header[end]) {
+		end++
+	}
+	typ, title, ok := parseCalloutHeader(header[:end], header[end:])
+	if !ok {
+		return nil, parser.NoChildren
+	}
+	advanceLine(reader, line, segment)
+	return &callout{CalloutType: typ, Title: title, fence: fence}, parser.HasChildren
+}
+
+// Continue implements parser.BlockParser
+func (p *calloutContainerParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	w, pos := util.IndentWidth(line, reader.LineOffset())
+	if w <= 3 && pos < len(line) && !inOpenCodeFence(node, pc) {
+		if fence := colonFence(line[pos:]); fence >= node.(*callout).fence && util.IsBlank(line[pos+fence:]) {
+			advanceLine(reader, line, segment)
+			return parser.Close
+		}
+	}
+	return parser.Continue | parser.HasChildren
+}
+
+// inOpenCodeFence reports whether a fenced code block inside node is still
+// open, in which case a colon line is code rather than the closing fence
+func inOpenCodeFence(node ast.Node, pc parser.Context) bool {
+	for _, b := range pc.OpenedBlocks() {
+		if b.Node.Kind() != ast.KindFencedCodeBlock {
+			continue
+		}
+		for p := b.Node.Parent(); p != nil; p = p.Parent() {
+			if p == node {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Close implements parser.BlockParser
+func (p *calloutContainerParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser
+func (p *calloutContainerParser) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser
+func (p *calloutContainerParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// calloutRenderer writes callouts as asides with an icon and title
+type calloutRenderer struct{}
+
+// RegisterFuncs implements renderer.NodeRenderer
+func (r *calloutRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindCallout, r.render)
+}
+
+func (r *calloutRenderer) render(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		_, _ = w.WriteString("</aside>\n")
+		return ast.WalkContinue, nil
+	}
+	c := n.(*callout)
+	typ := calloutTypes[c.CalloutType]
+	title := c.Title
+	if title == "" {
+		title = typ.title
+	}
+	_, _ = w.WriteString(`<aside class="callout callout-` + c.CalloutType + `">` + "\n")
+	_, _ = w.WriteString(`<p class="callout-title"><svg class="callout-icon" viewBox="0 0 16 16" width="16" height="16" aria-hidden="true" fill="none" stroke="currentColor" stroke-width="1.5" stroke-linecap="round" stroke-linejoin="round">`)
+	_, _ = w.WriteString(typ.icon)
+	_, _ = w.WriteString(`</svg>`)
+	_, _ = w.Write(util.EscapeHTML([]byte(title)))
+	_, _ = w.WriteString("</p>\n")
+	return ast.WalkContinue, nil
+}
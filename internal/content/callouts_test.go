@@ -0,0 +1,94 @@
+package content
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// calloutIcon matches the icon markup, which the expectations leave out
+var calloutIcon = regexp.MustCompile(`<svg class="callout-icon".*?</svg>`)
+
+func TestRenderCallouts(t *testing.T) {
+	renderer := NewRenderer()
+
+	testCases := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{
+			name:     "github alert",
+			markdown: "> [!NOTE]\n> Body *text*.\n\nAfter.",
+			expected: "<aside class=\"callout callout-note\">\n<p class=\"callout-title\">Note</p>\n<p>Body <em>text</em>.</p>\n</aside>\n<p>After.</p>",
+		},
+		{
+			name:     "alert type is case-insensitive with a custom title",
+			markdown: "> [!warning] Mind the gap\n> Body\n",
+			expected: "<aside class=\"callout callout-warning\">\n<p class=\"callout-title\">Mind the gap</p>\n<p>Body</p>\n</aside>",
+		},
+		{
+			name:     "unknown alert stays a blockquote",
+			markdown: "> [!BOGUS]\n> Body\n",
+			expected: "<blockquote>\n<p>[!BOGUS]<br />\nBody</p>\n</blockquote>",
+		},
+		{
+			name:     "plain blockquote unchanged",
+			markdown: "> Quoted\n",
+			expected: "<blockquote>\n<p>Quoted</p>\n</blockquote>",
+		},
+		{
+			name:     "container",
+			markdown: ":::tip\nUse **this**.\n\n- a\n:::\n\nAfter.",
+			expected: "<aside class=\"callout callout-tip\">\n<p class=\"callout-title\">Tip</p>\n<p>Use <strong>this</strong>.</p>\n<ul>\n<li>a</li>\n</ul>\n</aside>\n<p>After.</p>",
+		},
+		{
+			name:     "nested containers",
+			markdown: "::::note Outer\n:::caution\nInner\n:::\nOuter body\n::::\n",
+			expected: "<aside class=\"callout callout-note\">\n<p class=\"callout-title\">Outer</p>\n<aside class=\"callout callout-caution\">\n<p class=\"callout-title\">Caution</p>\n<p>Inner</p>\n</aside>\n<p>Outer body</p>\n</aside>",
+		},
+		{
+			name:     "colon line inside a code fence",
+			markdown: ":::note\n```\n:::\n```\nStill inside.\n:::\n",
+			expected: "<aside class=\"callout callout-note\">\n<p class=\"callout-title\">Note</p>\n<pre><code>:::\n</code></pre>\n<p>Still inside.</p>\n</aside>",
+		},
+		{
+			name:     "title is escaped",
+			markdown: ":::important <b>Read</b>\nBody\n:::\n",
+			expected: "<aside class=\"callout callout-important\">\n<p class=\"callout-title\">&lt;b&gt;Read&lt;/b&gt;</p>\n<p>Body</p>\n</aside>",
+		},
+		{
+			name:     "unknown container stays text",
+			markdown: ":::aside\nBody\n:::\n",
+			expected: "<p>:::aside<br />\nBody<br />\n:::</p>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			html, err := renderer.Render(tc.markdown)
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if got := strings.TrimSpace(calloutIcon.ReplaceAllString(html, "")); got != tc.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCalloutsSurviveSanitizer(t *testing.T) {
+	renderer := NewRenderer()
+
+	html, err := renderer.Render("> [!CAUTION]\n> Hot.\n")
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	sanitized := DefaultSanitizer().Sanitize(html)
+	if !strings.Contains(sanitized, `<aside class="callout callout-caution">`) {
+		t.Errorf("Expected the aside kept, got %s", sanitized)
+	}
+	if !strings.Contains(sanitized, `<svg class="callout-icon"`) || !strings.Contains(sanitized, `<path d="M5.25 1.5h5.5`) {
+		t.Errorf("Expected the icon kept, got %s", sanitized)
+	}
+}
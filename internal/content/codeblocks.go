@@ -0,0 +1,142 @@
+package content
+
+import (
+	"strconv"
+	"strings"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/util"
+)
+
+// Code fences take attributes after the language:
+//
+//	```go {title="main.go" linenos=false hl_lines="3-5" start=10}
+//
+// title adds a filename caption, linenos=false drops the line numbers (table
+// and inline pick their layout), start numbers lines from a value other than
+// 1 and hl_lines highlights lines counted from the top of the block, as a
+// list ([2, 3], [2, "4-6"]) or a string ("3-5", "2,4-6"). goldmark-highlighting
+// handles linenos itself; the rest is done here.
+
+// codeBlockOptions returns the chroma options for a fence's start and
+// hl_lines attributes. They are applied after goldmark-highlighting's own, so
+// they win where both read the same attribute.
+func codeBlockOptions(ctx highlighting.CodeBlockContext) []chromahtml.Option {
+	attrs := ctx.Attributes()
+	if attrs == nil {
+		return nil
+	}
+
+	var opts []chromahtml.Option
+	base := 1
+	if v, ok := attrs.GetString("start"); ok {
+		if n, ok := v.(float64); ok {
+			base = int(n)
+			opts = append(opts, chromahtml.BaseLineNumber(base))
+		}
+	} else if v, ok := attrs.GetString("linenostart"); ok {
+		if n, ok := v.(float64); ok {
+			base = int(n)
+		}
+	}
+	if v, ok := attrs.GetString("hl_lines"); ok {
+		ranges := lineRanges(v)
+		for i := range ranges {
+			ranges[i][0] += base - 1
+			ranges[i][1] += base - 1
+		}
+		opts = append(opts, chromahtml.HighlightLines(ranges))
+	}
+	return opts
+}
+
+// lineRanges parses an hl_lines value into inclusive line ranges. Malformed
+// entries are skipped.
+func lineRanges(v interface{}) [][2]int {
+	var ranges [][2]int
+	switch v := v.(type) {
+	case float64:
+		ranges = append(ranges, [2]int{int(v), int(v)})
+	case []byte:
+		for _, field := range strings.FieldsFunc(string(v), func(r rune) bool { return r == ',' || r == ' ' }) {
+			if r, ok := lineRange(field); ok {
+				ranges = append(ranges, r)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			ranges = append(ranges, lineRanges(item)...)
+		}
+	}
+	return ranges
+}
+
+// lineRange parses "3" or "3-5"
+func lineRange(s string) ([2]int, bool) {
+	from, to, isRange := strings.Cut(s, "-")
+	lo, err := strconv.Atoi(strings.TrimSpace(from))
+	if err != nil {
+		return [2]int{}, false
+	}
+	hi := lo
+	if isRange {
+		if hi, err = strconv.Atoi(strings.TrimSpace(to)); err != nil || hi < lo {
+			return [2]int{}, false
+		}
+	}
+	return [2]int{lo, hi}, true
+}
+
+// codeBlockWrapper wraps a code block with a title in a figure captioned
+// with it. Blocks chroma cannot highlight get the <pre><code> goldmark
+// would write, since a wrapper renderer replaces it.
+func codeBlockWrapper(w util.BufWriter, ctx highlighting.CodeBlockContext, entering bool) {
+	title := codeBlockTitle(ctx)
+	if entering {
+		if title != "" {
+			_, _ = w.WriteString(`<figure class="code">` + "\n" + `<figcaption class="code-title">`)
+			_, _ = w.Write(util.EscapeHTML([]byte(title)))
+			_, _ = w.WriteString("</figcaption>\n")
+		}
+		if !ctx.Highlighted() {
+			_, _ = w.WriteString("<pre><code")
+			if lang, ok := ctx.Language(); ok {
+				_, _ = w.WriteString(` class="language-`)
+				_, _ = w.Write(util.EscapeHTML(lang))
+				_ = w.WriteByte('"')
+			}
+			_ = w.WriteByte('>')
+		}
+		return
+	}
+
+	if !ctx.Highlighted() {
+		_, _ = w.WriteString("</code></pre>\n")
+	}
+	if title != "" {
+		if ctx.Highlighted() {
+			_ = w.WriteByte('\n') // chroma leaves the line open after </pre>
+		}
+		_, _ = w.WriteString("</figure>\n")
+	}
+}
+
+// codeBlockTitle returns the title attribute of a code block, if any
+func codeBlockTitle(ctx highlighting.CodeBlockContext) string {
+	attrs := ctx.Attributes()
+	if attrs == nil {
+		return ""
+	}
+	v, ok := attrs.GetString("title")
+	if !ok {
+		return ""
+	}
+	switch v := v.(type) {
+	case []byte:
+		return strings.TrimSpace(string(v))
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
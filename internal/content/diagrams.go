@@ -0,0 +1,243 @@
+package content
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// DiagramRenderer turns the source of a diagram fence, e.g. ```mermaid, into
+// the HTML placed inside its <figure class="diagram">. The output goes
+// through the sanitiser like the rest of the post, which keeps inline SVG.
+type DiagramRenderer interface {
+	RenderDiagram(source []byte) ([]byte, error)
+}
+
+// ScriptedDiagram is implemented by diagram renderers whose output is drawn
+// in the browser by mermaid.js, the diagram script pages load on demand.
+// NeedsScript reports whether the page needs it.
+type ScriptedDiagram interface {
+	DiagramRenderer
+	NeedsScript() bool
+}
+
+// WithScript marks the output of r as drawn by mermaid.js, for renderers
+// such as a DiagramFunc that emit <pre class="mermaid"> themselves
+func WithScript(r DiagramRenderer) ScriptedDiagram {
+	return scriptedDiagram{r}
+}
+
+// scriptedDiagram is the ScriptedDiagram returned by WithScript
+type scriptedDiagram struct {
+	DiagramRenderer
+}
+
+// NeedsScript implements ScriptedDiagram
+func (scriptedDiagram) NeedsScript() bool {
+	return true
+}
+
+// DiagramFunc adapts a function to DiagramRenderer
+type DiagramFunc func(source []byte) ([]byte, error)
+
+// RenderDiagram implements DiagramRenderer
+func (f DiagramFunc) RenderDiagram(source []byte) ([]byte, error) {
+	return f(source)
+}
+
+// MermaidDiagram leaves Mermaid diagrams to mermaid.js in the browser, which
+// replaces each <pre class="mermaid"> with the drawn SVG
+type MermaidDiagram struct{}
+
+// RenderDiagram implements DiagramRenderer
+func (MermaidDiagram) RenderDiagram(source []byte) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(`<pre class="mermaid">`)
+	b.Write(util.EscapeHTML(source))
+	b.WriteString("</pre>")
+	return b.Bytes(), nil
+}
+
+// NeedsScript implements ScriptedDiagram
+func (MermaidDiagram) NeedsScript() bool {
+	return true
+}
+
+// CommandDiagram renders diagrams with an external program that reads the
+// source on stdin and writes SVG to stdout, such as Graphviz's dot -Tsvg
+type CommandDiagram struct {
+	Name    string
+	Args    []string
+	Timeout time.Duration // Zero means ten seconds
+}
+
+// RenderDiagram implements DiagramRenderer
+func (c CommandDiagram) RenderDiagram(source []byte) ([]byte, error) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Stdin = bytes.NewReader(source)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", c.Name, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", c.Name, err)
+	}
+
+	// Drop the XML declaration, doctype and comments that precede the <svg>
+	i := bytes.Index(out, []byte("<svg"))
+	if i < 0 {
+		return nil, fmt.Errorf("%s: output is not SVG", c.Name)
+	}
+	return out[i:], nil
+}
+
+// DefaultDiagrams returns the diagram renderers NewRenderer starts with:
+// Mermaid in the browser and Graphviz dot on the server
+func DefaultDiagrams() map[string]DiagramRenderer {
+	return map[string]DiagramRenderer{
+		"mermaid": MermaidDiagram{},
+		"dot":     CommandDiagram{Name: "dot", Args: []string{"-Tsvg"}},
+	}
+}
+
+// diagramWarningsKey carries the diagrams that failed to render
+var diagramWarningsKey = parser.NewContextKey()
+
+// mermaidKey is set when a ScriptedDiagram needs mermaid.js, so the page loads it
+var mermaidKey = parser.NewContextKey()
+
+// kindDiagram is the AST node kind of a rendered diagram fence
+var kindDiagram = ast.NewNodeKind("Diagram")
+
+// diagram replaces a fenced code block whose language has a DiagramRenderer
+type diagram struct {
+	ast.BaseBlock
+	Language string
+	HTML     []byte
+}
+
+// Kind implements ast.Node
+func (n *diagram) Kind() ast.NodeKind {
+	return kindDiagram
+}
+
+// Dump implements ast.Node
+func (n *diagram) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Language": n.Language}, nil)
+}
+
+// diagrams is a goldmark extension rendering diagram fences as figures.
+// Other fences are left to the highlighter.
+type diagrams struct {
+	renderers map[string]DiagramRenderer
+}
+
+// Extend implements goldmark.Extender
+func (e *diagrams) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(&diagramTransformer{renderers: e.renderers}, 150),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&diagramRenderer{}, 500),
+	))
+}
+
+// diagramTransformer renders each diagram fence and swaps it for a diagram
+// node. A fence that fails to render stays a highlighted code block and the
+// failure is recorded as a warning.
+type diagramTransformer struct {
+	renderers map[string]DiagramRenderer
+}
+
+// Transform implements parser.ASTTransformer
+func (t *diagramTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	if len(t.renderers) == 0 {
+		return
+	}
+	source := reader.Source()
+
+	var fences []*ast.FencedCodeBlock
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if fence, ok := n.(*ast.FencedCodeBlock); ok && entering {
+			fences = append(fences, fence)
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	var warnings []string
+	for _, fence := range fences {
+		lang := string(fence.Language(source))
+		r, ok := t.renderers[lang]
+		if !ok || r == nil {
+			continue
+		}
+
+		var src bytes.Buffer
+		lines := fence.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			seg := lines.At(i)
+			src.Write(seg.Value(source))
+		}
+		html, err := r.RenderDiagram(src.Bytes())
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s diagram on line %d not rendered: %v", lang, fenceLine(fence, source), err))
+			continue
+		}
+		fence.Parent().ReplaceChild(fence.Parent(), fence, &diagram{Language: lang, HTML: html})
+		if s, ok := r.(ScriptedDiagram); ok && s.NeedsScript() {
+			pc.Set(mermaidKey, true)
+		}
+	}
+	if len(warnings) > 0 {
+		pc.Set(diagramWarningsKey, warnings)
+	}
+}
+
+// fenceLine returns the 1-based line of a fence's opening ``` in source
+func fenceLine(fence *ast.FencedCodeBlock, source []byte) int {
+	if fence.Info == nil {
+		return 0
+	}
+	return bytes.Count(source[:fence.Info.Segment.Start], []byte("\n")) + 1
+}
+
+// diagramRenderer writes a rendered diagram inside a figure
+type diagramRenderer struct{}
+
+// RegisterFuncs implements renderer.NodeRenderer
+func (r *diagramRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindDiagram, r.render)
+}
+
+func (r *diagramRenderer) render(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	d := n.(*diagram)
+	_, _ = w.WriteString(`<figure class="diagram diagram-`)
+	_, _ = w.Write(util.EscapeHTML([]byte(d.Language)))
+	_, _ = w.WriteString(`">`)
+	_, _ = w.Write(d.HTML)
+	_, _ = w.WriteString("</figure>\n")
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,149 @@
+package content
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderDiagrams(t *testing.T) {
+	renderer := NewRenderer()
+	renderer.SetDiagramRenderer("boxes", DiagramFunc(func(source []byte) ([]byte, error) {
+		return []byte(`<svg viewBox="0 0 1 1"><text>` + strings.TrimSpace(string(source)) + `</text></svg>`), nil
+	}))
+	renderer.SetDiagramRenderer("flow", WithScript(DiagramFunc(func(source []byte) ([]byte, error) {
+		return []byte(`<pre class="mermaid">flowchart LR</pre>`), nil
+	})))
+
+	testCases := []struct {
+		name     string
+		markdown string
+		expected string
+		mermaid  bool
+	}{
+		{
+			name:     "mermaid left to the browser",
+			markdown: "```mermaid\ngraph TD\n  A --> B\n```\n",
+			expected: "<figure class=\"diagram diagram-mermaid\"><pre class=\"mermaid\">graph TD\n  A --&gt; B\n</pre></figure>",
+			mermaid:  true,
+		},
+		{
+			name:     "custom renderer needing mermaid.js",
+			markdown: "```flow\nA to B\n```\n",
+			expected: `<figure class="diagram diagram-flow"><pre class="mermaid">flowchart LR</pre></figure>`,
+			mermaid:  true,
+		},
+		{
+			name:     "custom renderer",
+			markdown: "```boxes\nhello\n```\n",
+			expected: `<figure class="diagram diagram-boxes"><svg viewBox="0 0 1 1"><text>hello</text></svg></figure>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := renderer.RenderDocument(tc.markdown, RenderOptions{})
+			if err != nil {
+				t.Fatalf("RenderDocument failed: %v", err)
+			}
+			if got := strings.TrimSpace(doc.HTML); got != tc.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tc.expected, got)
+			}
+			if len(doc.Warnings) != 0 {
+				t.Errorf("Expected no warnings, got %v", doc.Warnings)
+			}
+			if doc.Mermaid != tc.mermaid {
+				t.Errorf("Expected Mermaid = %v, got %v", tc.mermaid, doc.Mermaid)
+			}
+		})
+	}
+}
+
+func TestRenderDiagramsLeaveOtherFencesHighlighted(t *testing.T) {
+	renderer := NewRenderer()
+
+	html, err := renderer.Render("```go\nfunc main() {}\n```\n\n```\nplain\n```\n")
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if strings.Contains(html, "diagram") || !strings.Contains(html, `<pre class="chroma">`) {
+		t.Errorf("Expected code highlighted as before, got %s", html)
+	}
+
+	renderer.SetDiagramRenderer("mermaid", nil)
+	html, err = renderer.Render("```mermaid\ngraph TD\n```\n")
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if strings.Contains(html, "diagram") {
+		t.Errorf("Expected mermaid highlighted as code once its renderer is removed, got %s", html)
+	}
+}
+
+func TestRenderDiagramFailureFallsBackToCode(t *testing.T) {
+	renderer := NewRenderer()
+	renderer.SetDiagramRenderer("dot", DiagramFunc(func([]byte) ([]byte, error) {
+		return nil, errors.New("syntax error")
+	}))
+
+	doc, err := renderer.RenderDocument("Intro.\n\n```dot\ndigraph { a -> }\n```\n", RenderOptions{})
+	if err != nil {
+		t.Fatalf("RenderDocument failed: %v", err)
+	}
+	if strings.Contains(doc.HTML, "<figure") || !strings.Contains(doc.HTML, "digraph") {
+		t.Errorf("Expected the source shown as code, got %s", doc.HTML)
+	}
+	if len(doc.Warnings) != 1 || doc.Warnings[0] != "dot diagram on line 3 not rendered: syntax error" {
+		t.Errorf("Unexpected warnings %q", doc.Warnings)
+	}
+}
+
+func TestCommandDiagram(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	svg := CommandDiagram{Name: "sh", Args: []string{"-c", `printf '<?xml version="1.0"?>\n<!DOCTYPE svg>\n<svg>'; cat; printf '</svg>'`}}
+	out, err := svg.RenderDiagram([]byte("<g/>"))
+	if err != nil {
+		t.Fatalf("RenderDiagram failed: %v", err)
+	}
+	if string(out) != "<svg><g/></svg>" {
+		t.Errorf("Expected the prolog stripped, got %q", out)
+	}
+
+	failing := CommandDiagram{Name: "sh", Args: []string{"-c", "echo 'bad input' >&2; exit 1"}}
+	if _, err := failing.RenderDiagram(nil); err == nil || !strings.Contains(err.Error(), "bad input") {
+		t.Errorf("Expected stderr in the error, got %v", err)
+	}
+
+	text := CommandDiagram{Name: "sh", Args: []string{"-c", "echo plain"}}
+	if _, err := text.RenderDiagram(nil); err == nil {
+		t.Error("Expected an error for output that is not SVG")
+	}
+}
+
+func TestLoadAllReportsDiagramWarnings(t *testing.T) {
+	tempDir := t.TempDir()
+	body := "---\ntitle: \"Graph\"\ndate: \"2025-09-01\"\n---\n\n```dot\ndigraph { a -> b }\n```\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "graph.md"), []byte(body), 0644); err != nil {
+		t.Fatalf("Failed to write graph.md: %v", err)
+	}
+
+	loader := NewLoader(tempDir, "")
+	loader.SetDiagramRenderer("dot", DiagramFunc(func([]byte) ([]byte, error) {
+		return nil, errors.New("dot not installed")
+	}))
+	posts, err := loader.LoadAll()
+	if len(posts) != 1 {
+		t.Fatalf("Expected the post to load, got %d posts", len(posts))
+	}
+
+	loadErrs := AsLoadErrors(err)
+	if len(loadErrs) != 1 || !loadErrs[0].Warning || !strings.Contains(loadErrs[0].Reason, "dot not installed") {
+		t.Errorf("Expected a diagram warning, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LoadError describes a single content file that failed to load, or a
+// problem in a file that loaded anyway when Warning is set
+type LoadError struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line,omitempty"` // 1-based line in the source file; 0 when unknown
+	Reason  string `json:"reason"`
+	Warning bool   `json:"warning,omitempty"` // The file loaded; e.g. a broken wiki-link
+}
+
+// Error implements the error interface
+func (e LoadError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Reason)
+}
+
+// LoadErrors collects per-file failures from a content load.
+// LoadAll returns it alongside every post that did load successfully.
+type LoadErrors []LoadError
+
+// Error implements the error interface
+func (e LoadErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "no content errors"
+	case 1:
+		return e[0].Error()
+	}
+
+	msgs := make([]string, len(e))
+	for i, le := range e {
+		msgs[i] = le.Error()
+	}
+	failed := len(e.Failed())
+	warnings := len(e) - failed
+
+	var summary string
+	switch {
+	case warnings == 0:
+		summary = fmt.Sprintf("%d content files failed to load", failed)
+	case failed == 0:
+		summary = fmt.Sprintf("%d content warnings", warnings)
+	default:
+		summary = fmt.Sprintf("%s failed to load, %s", plural(failed, "content file"), plural(warnings, "warning"))
+	}
+	return summary + ":\n" + strings.Join(msgs, "\n")
+}
+
+// plural formats n with noun, adding an s unless n is 1
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// Failed returns the errors for files that were skipped, leaving out warnings
+func (e LoadErrors) Failed() LoadErrors {
+	var failed LoadErrors
+	for _, le := range e {
+		if !le.Warning {
+			failed = append(failed, le)
+		}
+	}
+	return failed
+}
+
+// AsLoadErrors extracts the per-file failures from an error returned by LoadAll.
+// It returns nil when err carries no file-level failures.
+func AsLoadErrors(err error) LoadErrors {
+	var errs LoadErrors
+	if errors.As(err, &errs) {
+		return errs
+	}
+	return nil
+}
+
+// lineError attaches a source line number to a parse error
+type lineError struct {
+	line int
+	err  error
+}
+
+func (e *lineError) Error() string { return e.err.Error() }
+func (e *lineError) Unwrap() error { return e.err }
+
+// yamlLineRegex matches the line references emitted by yaml.v3 errors
+var yamlLineRegex = regexp.MustCompile(`line (\d+)`)
+
+// newLoadError builds a LoadError for path, recovering the line number when known
+func newLoadError(path string, err error) LoadError {
+	le := LoadError{Path: path, Reason: err.Error()}
+	var lerr *lineError
+	if errors.As(err, &lerr) {
+		le.Line = lerr.line
+	}
+	return le
+}
+
+// yamlErrorLine returns the line reported in a yaml.v3 error, offset by the
+// number of lines that precede the YAML block in the file
+func yamlErrorLine(err error, offset int) int {
+	m := yamlLineRegex.FindStringSubmatch(err.Error())
+	if len(m) < 2 {
+		return 0
+	}
+	n, convErr := strconv.Atoi(m[1])
+	if convErr != nil {
+		return 0
+	}
+	return n + offset
+}
+
+// frontMatterKeyLine returns the 1-based line of a top-level front matter key,
+// or 0 when the key is not present
+func frontMatterKeyLine(content, key string) int {
+	if !strings.HasPrefix(content, "---\n") {
+		return 0
+	}
+	lines := strings.Split(content, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] == "---" {
+			break
+		}
+		if strings.HasPrefix(lines[i], key+":") {
+			return i + 1
+		}
+	}
+	return 0
+}
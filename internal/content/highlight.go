@@ -0,0 +1,72 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// Default chroma styles for highlighted code. Rendered HTML carries only
+// token classes, so the style is a matter of the CSS served alongside it.
+const (
+	DefaultHighlightStyle     = "github"
+	DefaultHighlightDarkStyle = "github-dark"
+)
+
+// HighlightCSS returns the stylesheet for highlighted code blocks. With a
+// dark style the light palette applies unless the reader prefers a dark
+// colour scheme, and the dark one when they do; the two never mix, so a
+// token the dark style leaves plain is not coloured for a light background.
+// An empty light style is DefaultHighlightStyle, an empty dark style leaves
+// the light palette unconditional. Unknown style names are an error.
+func HighlightCSS(light, dark string) (string, error) {
+	if light == "" {
+		light = DefaultHighlightStyle
+	}
+	lightStyle, err := lookupStyle(light)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if dark == "" {
+		if err := writeStyleCSS(&buf, lightStyle); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+
+	darkStyle, err := lookupStyle(dark)
+	if err != nil {
+		return "", err
+	}
+	buf.WriteString("@media not all and (prefers-color-scheme: dark) {\n")
+	if err := writeStyleCSS(&buf, lightStyle); err != nil {
+		return "", err
+	}
+	buf.WriteString("}\n@media (prefers-color-scheme: dark) {\n")
+	if err := writeStyleCSS(&buf, darkStyle); err != nil {
+		return "", err
+	}
+	buf.WriteString("}\n")
+	return buf.String(), nil
+}
+
+// lookupStyle returns the registered chroma style called name
+func lookupStyle(name string) (*chroma.Style, error) {
+	style, ok := styles.Registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown highlight style %q", name)
+	}
+	return style, nil
+}
+
+// writeStyleCSS writes the class rules for style, including line numbers and
+// highlighted lines
+func writeStyleCSS(buf *bytes.Buffer, style *chroma.Style) error {
+	formatter := html.New(html.WithClasses(true), html.WithLineNumbers(true))
+	return formatter.WriteCSS(buf, style)
+}
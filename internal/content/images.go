@@ -0,0 +1,100 @@
+package content
+
+import (
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	"notebook.oceanheart.ai/internal/imaging"
+)
+
+// assetDirKey carries the filesystem directory a bundle's assets live in
+var assetDirKey = parser.NewContextKey()
+
+// imageSizes matches the post column width in app.css (#single)
+const imageSizes = "(max-width: 680px) 100vw, 680px"
+
+// responsiveImages is a goldmark extension that marks every image for lazy
+// loading and gives bundle images intrinsic dimensions plus a srcset of
+// resized WebP variants served from /img/
+type responsiveImages struct {
+	widths []int
+}
+
+// Extend implements goldmark.Extender
+func (e *responsiveImages) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		// Runs after bundleAssetTransformer has resolved relative destinations
+		util.Prioritized(&responsiveImageTransformer{widths: e.widths}, 200),
+	))
+}
+
+// responsiveImageTransformer sets the <img> attributes rendered by goldmark
+type responsiveImageTransformer struct {
+	widths []int
+}
+
+// Transform implements parser.ASTTransformer
+func (t *responsiveImageTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	base, _ := pc.Get(assetBaseKey).(string)
+	dir, _ := pc.Get(assetDirKey).(string)
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		img, ok := n.(*ast.Image)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		img.SetAttributeString("loading", []byte("lazy"))
+		img.SetAttributeString("decoding", []byte("async"))
+
+		if base != "" && dir != "" {
+			t.addVariants(img, base, dir)
+		}
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// addVariants sets width, height and srcset for an image stored in the bundle
+func (t *responsiveImageTransformer) addVariants(img *ast.Image, base, dir string) {
+	dest, err := url.Parse(string(img.Destination))
+	if err != nil || dest.Scheme != "" || dest.Host != "" {
+		return
+	}
+
+	file := strings.TrimPrefix(dest.Path, strings.TrimSuffix(base, "/")+"/")
+	if file == dest.Path || !imaging.IsImage(file) {
+		return
+	}
+
+	width, height, err := imaging.Dimensions(filepath.Join(dir, filepath.FromSlash(file)))
+	if err != nil {
+		return
+	}
+	img.SetAttributeString("width", []byte(strconv.Itoa(width)))
+	img.SetAttributeString("height", []byte(strconv.Itoa(height)))
+
+	variants := imaging.VariantWidths(t.widths, width)
+	if len(variants) == 0 {
+		return
+	}
+
+	slug := bundleSlug(base)
+	candidates := make([]string, 0, len(variants)+1)
+	for _, w := range variants {
+		candidates = append(candidates, imaging.VariantURL(slug, file, w)+" "+strconv.Itoa(w)+"w")
+	}
+	candidates = append(candidates, dest.Path+" "+strconv.Itoa(width)+"w")
+
+	img.SetAttributeString("srcset", []byte(strings.Join(candidates, ", ")))
+	img.SetAttributeString("sizes", []byte(imageSizes))
+}
@@ -16,7 +16,7 @@ func TestContentPipelineIntegration(t *testing.T) {
 	defer db.Close()
 
 	// Test content loading
-	loader := NewLoader("../../content")
+	loader := NewLoader("../../content", "")
 	posts, err := loader.LoadAll()
 	if err != nil {
 		t.Fatalf("LoadAll failed: %v", err)
@@ -1,92 +1,212 @@
 package content
 
 import (
-	"regexp"
+	"net/url"
 	"strings"
+
+	"golang.org/x/net/html"
 )
 
+// LinkPolicy sets the attributes added to links that leave the site
+type LinkPolicy struct {
+	Target string   // target for external links, e.g. "_blank"; empty adds none
+	Rel    string   // rel for external links, e.g. "noopener noreferrer"; empty adds none
+	Hosts  []string // Further hosts treated as internal, e.g. "www.example.com"
+}
+
+// DefaultLinkPolicy opens external links in a new tab without giving the
+// target page access to the opener or the referrer
+func DefaultLinkPolicy() LinkPolicy {
+	return LinkPolicy{Target: "_blank", Rel: "noopener noreferrer"}
+}
+
 // ProcessExternalLinks adds security attributes to external links in HTML
+// using the default policy
 func ProcessExternalLinks(html string, baseURL string) string {
-	// Regex to match <a href="..."> tags
-	linkRegex := regexp.MustCompile(`<a\s+([^>]*\s+)?href="([^"]+)"([^>]*)>`)
-	
-	return linkRegex.ReplaceAllStringFunc(html, func(match string) string {
-		// Extract href URL from the match
-		hrefRegex := regexp.MustCompile(`href="([^"]+)"`)
-		hrefMatch := hrefRegex.FindStringSubmatch(match)
-		if len(hrefMatch) < 2 {
-			return match // Return original if we can't extract URL
+	return DefaultLinkPolicy().Apply(html, baseURL)
+}
+
+// Apply adds the policy's target and rel to every <a> in s whose href points
+// off the site at baseURL. Attributes a link already has are left alone, and
+// everything other than the rewritten tags is copied through unchanged.
+func (p LinkPolicy) Apply(s string, baseURL string) string {
+	if p.Target == "" && p.Rel == "" {
+		return s
+	}
+
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			// io.EOF, or a read error that cannot happen on a strings.Reader
+			return b.String()
 		}
-		
-		url := hrefMatch[1]
-		
-		// Check if it's an external link
-		if isExternalLink(url, baseURL) {
-			// Check if it already has target and rel attributes
-			hasTarget := strings.Contains(match, "target=")
-			hasRel := strings.Contains(match, "rel=")
-			
-			// Add attributes if missing
-			var additions []string
-			if !hasTarget {
-				additions = append(additions, `target="_blank"`)
-			}
-			if !hasRel {
-				additions = append(additions, `rel="noopener noreferrer"`)
+
+		// Copy the raw token before Token() lowercases the buffer in place
+		raw := string(z.Raw())
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			b.WriteString(raw)
+			continue
+		}
+		tok := z.Token()
+		if tok.Data != "a" {
+			b.WriteString(raw)
+			continue
+		}
+
+		var href string
+		var hasTarget, hasRel bool
+		for _, a := range tok.Attr {
+			switch a.Key {
+			case "href":
+				if href == "" {
+					href = a.Val // Browsers use the first of duplicate attributes
+				}
+			case "target":
+				hasTarget = true
+			case "rel":
+				hasRel = true
 			}
-			
-			if len(additions) > 0 {
-				// Insert additions before the closing >
-				closingIndex := strings.LastIndex(match, ">")
-				if closingIndex != -1 {
-					return match[:closingIndex] + " " + strings.Join(additions, " ") + match[closingIndex:]
+		}
+		if !p.isExternal(href, baseURL) {
+			b.WriteString(raw)
+			continue
+		}
+
+		if p.Target != "" && !hasTarget {
+			tok.Attr = append(tok.Attr, html.Attribute{Key: "target", Val: p.Target})
+		}
+		if p.Rel != "" && !hasRel {
+			tok.Attr = append(tok.Attr, html.Attribute{Key: "rel", Val: p.Rel})
+		}
+		writeTag(&b, tok)
+	}
+}
+
+// writeTag writes a start or self-closing tag with double-quoted attributes
+func writeTag(b *strings.Builder, tok html.Token) {
+	b.WriteByte('<')
+	b.WriteString(tok.Data)
+	for _, a := range tok.Attr {
+		b.WriteByte(' ')
+		b.WriteString(a.Key)
+		b.WriteString(`="`)
+		b.WriteString(escapeHTML(a.Val))
+		b.WriteByte('"')
+	}
+	if tok.Type == html.SelfClosingTagToken {
+		b.WriteString(" /")
+	}
+	b.WriteByte('>')
+}
+
+// PostLinks returns the slugs of the posts that <a> elements in s link to,
+// in first use order: hrefs of the form /p/{slug}, relative or on the host of
+// baseURL, with any query or fragment. Links to bundle files such as
+// /p/{slug}/diagram.png are not links to the post.
+func PostLinks(s string, baseURL string) []string {
+	var slugs []string
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return uniqueStrings(slugs)
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		name, hasAttr := z.TagName()
+		if string(name) != "a" || !hasAttr {
+			continue
+		}
+		for {
+			key, val, more := z.TagAttr()
+			if string(key) == "href" {
+				if slug := postSlug(string(val), baseURL); slug != "" {
+					slugs = append(slugs, slug)
 				}
+				break // Browsers use the first of duplicate attributes
+			}
+			if !more {
+				break
 			}
 		}
-		
-		return match
-	})
+	}
+}
+
+// postSlug returns the slug of the post at href, or "" when href is not a
+// post URL on this site
+func postSlug(href, baseURL string) string {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil || u.Opaque != "" {
+		return ""
+	}
+	if u.Scheme != "" || u.Host != "" {
+		base, err := url.Parse(baseURL)
+		if err != nil || base.Host == "" || !strings.EqualFold(u.Hostname(), base.Hostname()) {
+			return ""
+		}
+		if u.Scheme != "" && !strings.EqualFold(u.Scheme, "http") && !strings.EqualFold(u.Scheme, "https") {
+			return ""
+		}
+	}
+	rest, ok := strings.CutPrefix(u.Path, "/p/")
+	if !ok {
+		return ""
+	}
+	slug := strings.TrimSuffix(rest, "/")
+	if slug == "" || strings.Contains(slug, "/") {
+		return ""
+	}
+	return slug
 }
 
-// isExternalLink determines if a URL is external to the base domain
-func isExternalLink(url, baseURL string) bool {
-	// Skip relative URLs, anchors, and protocol-relative URLs
-	if strings.HasPrefix(url, "/") || 
-	   strings.HasPrefix(url, "#") || 
-	   strings.HasPrefix(url, "//") {
+// isExternal reports whether href leaves the site, allowing the policy's
+// extra internal hosts
+func (p LinkPolicy) isExternal(href, baseURL string) bool {
+	if !isExternalLink(href, baseURL) {
 		return false
 	}
-	
-	// Skip mailto and other non-HTTP protocols
-	if strings.HasPrefix(url, "mailto:") || 
-	   strings.HasPrefix(url, "tel:") ||
-	   strings.HasPrefix(url, "javascript:") {
+	u, _ := url.Parse(strings.TrimSpace(href)) // isExternalLink parsed it already
+	for _, host := range p.Hosts {
+		if strings.EqualFold(u.Hostname(), host) {
+			return false
+		}
+	}
+	return true
+}
+
+// isExternalLink determines if a URL is external to the base domain. Only
+// absolute http(s) URLs can be external; relative, anchor, protocol-relative
+// and non-web (mailto:, tel:, ...) URLs are not rewritten. Hosts are compared
+// exactly, so subdomains and look-alikes such as example.com.evil.com are
+// external to example.com.
+func isExternalLink(rawURL, baseURL string) bool {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
 		return false
 	}
-	
-	// If URL doesn't start with http/https, assume it's relative
-	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+	if !strings.EqualFold(u.Scheme, "http") && !strings.EqualFold(u.Scheme, "https") {
 		return false
 	}
-	
-	// Extract domain from base URL
-	baseURLLower := strings.ToLower(baseURL)
-	urlLower := strings.ToLower(url)
-	
-	// Remove protocol from base URL to get domain
-	baseDomain := baseURLLower
-	if strings.HasPrefix(baseDomain, "https://") {
-		baseDomain = strings.TrimPrefix(baseDomain, "https://")
-	} else if strings.HasPrefix(baseDomain, "http://") {
-		baseDomain = strings.TrimPrefix(baseDomain, "http://")
+
+	base, err := url.Parse(baseURL)
+	if err != nil || base.Host == "" {
+		return true // Without a site host every absolute link leaves the site
 	}
-	
-	// Remove trailing path from base domain
-	if idx := strings.Index(baseDomain, "/"); idx != -1 {
-		baseDomain = baseDomain[:idx]
+	return !strings.EqualFold(u.Hostname(), base.Hostname())
+}
+
+// uniqueStrings returns s without repeats, keeping the first occurrence
+func uniqueStrings(s []string) []string {
+	seen := make(map[string]bool, len(s))
+	var out []string
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
 	}
-	
-	// Check if the URL starts with our base domain
-	return !strings.HasPrefix(urlLower, "https://"+baseDomain) && 
-		   !strings.HasPrefix(urlLower, "http://"+baseDomain)
-}
\ No newline at end of file
+	return out
+}
@@ -203,4 +203,91 @@ func TestProcessExternalLinksMalformedHTML(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestProcessExternalLinksAttributeForms(t *testing.T) {
+	baseURL := "https://example.com"
+
+	testCases := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "single-quoted href",
+			html:     `<a href='https://external.com/page'>Ext</a>`,
+			expected: `<a href="https://external.com/page" target="_blank" rel="noopener noreferrer">Ext</a>`,
+		},
+		{
+			name:     "unquoted href",
+			html:     `<a href=https://external.com/page>Ext</a>`,
+			expected: `<a href="https://external.com/page" target="_blank" rel="noopener noreferrer">Ext</a>`,
+		},
+		{
+			name:     "href after other attributes",
+			html:     `<a class="btn" href="https://external.com/page">Ext</a>`,
+			expected: `<a class="btn" href="https://external.com/page" target="_blank" rel="noopener noreferrer">Ext</a>`,
+		},
+		{
+			name:     "uppercase tag and attribute",
+			html:     `<A HREF="https://external.com/page">Ext</A>`,
+			expected: `<a href="https://external.com/page" target="_blank" rel="noopener noreferrer">Ext</A>`,
+		},
+		{
+			name:     "look-alike host is external",
+			html:     `<a href="https://example.com.evil.com/">Evil</a>`,
+			expected: `<a href="https://example.com.evil.com/" target="_blank" rel="noopener noreferrer">Evil</a>`,
+		},
+		{
+			name:     "same host with port is internal",
+			html:     `<a href="https://EXAMPLE.com:443/page">Int</a>`,
+			expected: `<a href="https://EXAMPLE.com:443/page">Int</a>`,
+		},
+		{
+			name:     "surrounding markup copied unchanged",
+			html:     `<p class='x'>a &amp; b<br/><img src=x.png></p>`,
+			expected: `<p class='x'>a &amp; b<br/><img src=x.png></p>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := ProcessExternalLinks(tc.html, baseURL)
+			if result != tc.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestLinkPolicy(t *testing.T) {
+	html := `<a href="https://www.example.com/">WWW</a> <a href="https://external.com/">Ext</a>`
+
+	policy := LinkPolicy{Rel: "nofollow noopener", Hosts: []string{"www.example.com"}}
+	expected := `<a href="https://www.example.com/">WWW</a> <a href="https://external.com/" rel="nofollow noopener">Ext</a>`
+	if result := policy.Apply(html, "https://example.com"); result != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, result)
+	}
+
+	if result := (LinkPolicy{}).Apply(html, "https://example.com"); result != html {
+		t.Errorf("Expected an empty policy to leave links unchanged, got %s", result)
+	}
+}
+
+func TestPostLinks(t *testing.T) {
+	html := `<a href="/p/alpha">A</a> <a href="https://example.com/p/beta/#intro">B</a>` +
+		` <a href="/p/alpha?x=1">A again</a> <a href="https://other.com/p/gamma">Other site</a>` +
+		` <a href="/p/delta/diagram.png">Bundle file</a> <a href="/tag/go">Tag</a>` +
+		` <a class="wikilink" href="/p/epsilon">E</a> <a name="p">No href</a> <img src="/p/zeta">`
+
+	got := strings.Join(PostLinks(html, "https://example.com"), ",")
+	if got != "alpha,beta,epsilon" {
+		t.Errorf("Expected alpha,beta,epsilon, got %q", got)
+	}
+
+	// Without a site host only relative links are known to be internal
+	got = strings.Join(PostLinks(html, ""), ",")
+	if got != "alpha,epsilon" {
+		t.Errorf("Expected alpha,epsilon without a base URL, got %q", got)
+	}
+}
@@ -6,61 +6,260 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"gopkg.in/yaml.v3"
+	"notebook.oceanheart.ai/internal/config"
 	"notebook.oceanheart.ai/internal/store"
+	"notebook.oceanheart.ai/internal/taxonomy"
 )
 
 // FrontMatter represents the YAML front matter of a markdown file
 type FrontMatter struct {
 	Title   string   `yaml:"title"`
 	Date    string   `yaml:"date"`
+	Updated string   `yaml:"updated"` // Date of the last significant revision; defaults to date
 	Tags    []string `yaml:"tags"`
 	Summary string   `yaml:"summary"`
 	Draft   bool     `yaml:"draft"`
+	TOC     bool     `yaml:"toc"`  // Show a table of contents above the post
+	Math    bool     `yaml:"math"` // Parse $TeX$ and load the math assets
+
+	TrustedHTML bool `yaml:"trusted_html"` // Skip HTML sanitisation for this file
+
+	Series      string `yaml:"series"`       // Name of the multi-part series the post belongs to
+	SeriesOrder int    `yaml:"series_order"` // Position within the series; 0 falls back to date order
 }
 
 // Loader handles loading and parsing markdown content from filesystem
 type Loader struct {
 	contentDir string
+	baseURL    string // Site URL; links to other hosts are external
 	renderer   *Renderer
+	location   *time.Location      // Zone for dates without an explicit offset
+	taxonomy   *taxonomy.Taxonomy  // Resolves tag aliases
+	sanitizer  *Sanitizer          // Cleans rendered HTML; nil disables
+	links      LinkPolicy          // Attributes added to external links
+	slugs      map[string]bool     // Posts wiki-links resolve against; nil until LoadAll
+	warnings   map[string][]string // Render warnings by file path, reported by LoadAll
 }
 
-// NewLoader creates a new content loader
-func NewLoader(contentDir string) *Loader {
+// NewLoader creates a new content loader for the site at baseURL. An empty
+// baseURL treats every absolute http(s) link as external.
+func NewLoader(contentDir, baseURL string) *Loader {
 	return &Loader{
 		contentDir: contentDir,
+		baseURL:    baseURL,
 		renderer:   NewRenderer(),
+		location:   time.UTC,
+		taxonomy:   taxonomy.Default(),
+		sanitizer:  DefaultSanitizer(),
+		links:      DefaultLinkPolicy(),
+	}
+}
+
+// NewLoaderFromConfig creates a loader with the site's content directory,
+// base URL, time zone, sanitisation and link settings, so every load path
+// renders posts the same way
+func NewLoaderFromConfig(cfg *config.Config) *Loader {
+	l := NewLoader(cfg.ContentDir, cfg.SiteBaseURL)
+	l.SetLocation(cfg.Location())
+	if !cfg.SanitizeHTML {
+		l.SetSanitizer(nil)
 	}
+	l.SetLinkPolicy(LinkPolicy{Target: cfg.LinkTarget, Rel: cfg.LinkRel})
+	return l
+}
+
+// SetDiagramRenderer renders code fences tagged lang with d, or highlights
+// them as code again when d is nil
+func (l *Loader) SetDiagramRenderer(lang string, d DiagramRenderer) {
+	l.renderer.SetDiagramRenderer(lang, d)
 }
 
-// LoadAll loads all markdown files from the content directory
+// SetLinkPolicy sets the target and rel added to external links
+func (l *Loader) SetLinkPolicy(p LinkPolicy) {
+	l.links = p
+}
+
+// SetSanitizer sets the policy rendered HTML is cleaned with before it is
+// stored. Nil disables sanitisation for every file.
+func (l *Loader) SetSanitizer(s *Sanitizer) {
+	l.sanitizer = s
+}
+
+// postProcess applies the post-render HTML transforms: sanitisation, unless
+// the file opted out with trusted_html: true, then external link attributes
+func (l *Loader) postProcess(html string, fm *FrontMatter, baseURL string) string {
+	if l.sanitizer != nil && !fm.TrustedHTML {
+		html = l.sanitizer.Sanitize(html)
+	}
+	return l.links.Apply(html, baseURL)
+}
+
+// SetLocation sets the time zone used for front matter dates that carry no
+// offset, such as "2025-09-17". Nil resets it to UTC.
+func (l *Loader) SetLocation(loc *time.Location) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	l.location = loc
+}
+
+// LoadTaxonomy reads the content directory's taxonomy file and uses it to
+// resolve tag aliases in subsequently loaded posts. Without a file the
+// built-in taxonomy applies. An invalid file is reported as LoadErrors and
+// the built-in taxonomy is returned so the site still renders.
+func (l *Loader) LoadTaxonomy() (*taxonomy.Taxonomy, error) {
+	path := filepath.Join(l.contentDir, taxonomy.FileName)
+	tax, err := taxonomy.Load(path)
+	if err != nil {
+		l.taxonomy = taxonomy.Default()
+		return l.taxonomy, LoadErrors{newLoadError(path, &lineError{line: yamlErrorLine(err, 0), err: err})}
+	}
+	l.taxonomy = tax
+	return tax, nil
+}
+
+// Site is everything loaded from the content directory
+type Site struct {
+	Taxonomy *taxonomy.Taxonomy
+	Posts    []*store.Post
+	Sections []*store.Section
+}
+
+// LoadSite loads the taxonomy, posts and sections in the order they depend on
+// each other. Files that fail to load are skipped and reported together as
+// LoadErrors alongside the rest of the site, and sections that cannot be read
+// are a warning. Any other error means the content directory could not be
+// read; the site then carries only the taxonomy.
+func (l *Loader) LoadSite() (*Site, error) {
+	tax, err := l.LoadTaxonomy()
+	loadErrs := AsLoadErrors(err)
+
+	posts, err := l.LoadAll()
+	if err != nil && AsLoadErrors(err) == nil {
+		return &Site{Taxonomy: tax}, fmt.Errorf("failed to load content: %w", err)
+	}
+	loadErrs = append(loadErrs, AsLoadErrors(err)...)
+
+	sections, err := l.LoadSections()
+	if sectionErrs := AsLoadErrors(err); err != nil && sectionErrs == nil {
+		loadErrs = append(loadErrs, LoadError{
+			Path:    l.contentDir,
+			Reason:  fmt.Sprintf("failed to load sections: %v", err),
+			Warning: true,
+		})
+	} else {
+		loadErrs = append(loadErrs, sectionErrs...)
+	}
+
+	site := &Site{Taxonomy: tax, Posts: posts, Sections: sections}
+	if len(loadErrs) > 0 {
+		return site, loadErrs
+	}
+	return site, nil
+}
+
+// LoadAll loads all markdown files from the content directory.
+// A file that fails to load does not abort the walk: every valid post is
+// returned, and the failures are reported together as LoadErrors. Links to
+// posts no file provides and diagrams that failed to render are reported as
+// warnings.
 func (l *Loader) LoadAll() ([]*store.Post, error) {
 	var posts []*store.Post
+	var loadErrs LoadErrors
 
-	err := filepath.Walk(l.contentDir, func(path string, info os.FileInfo, err error) error {
+	// Find every post first so wiki-links can be resolved while rendering
+	var paths []string
+	walkErr := filepath.Walk(l.contentDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			return err
+			loadErrs = append(loadErrs, newLoadError(path, err))
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
 		}
 
-		// Skip directories and non-markdown files
-		if info.IsDir() || !strings.HasSuffix(strings.ToLower(path), ".md") {
+		// A page bundle contributes only its index.md; sibling files are assets
+		if info.IsDir() {
+			if path != l.contentDir && isBundleDir(path) {
+				paths = append(paths, filepath.Join(path, bundleIndexFile))
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
-		post, err := l.LoadFile(path)
-		if err != nil {
-			return fmt.Errorf("failed to load %s: %w", path, err)
+		// Skip non-markdown files and section index files
+		if !strings.HasSuffix(strings.ToLower(path), ".md") {
+			return nil
 		}
-
-		if post != nil {
-			posts = append(posts, post)
+		if isSectionIndex(path) {
+			return nil
 		}
 
+		paths = append(paths, path)
 		return nil
 	})
+	if walkErr != nil {
+		return posts, walkErr
+	}
+
+	l.warnings = make(map[string][]string)
+	defer func() { l.warnings = nil }()
+	// Files in different sections can share a slug; the first in walk order
+	// keeps it and the others are skipped rather than overwriting it
+	l.slugs = make(map[string]bool, len(paths))
+	owners := make(map[string]string, len(paths))
+	unique := make([]string, 0, len(paths))
+	for _, path := range paths {
+		slug := l.generateSlug(path)
+		if owner, ok := owners[slug]; ok {
+			loadErrs = append(loadErrs, LoadError{
+				Path:   path,
+				Reason: fmt.Sprintf("slug %q is already used by %s", slug, owner),
+			})
+			continue
+		}
+		owners[slug] = path
+		l.slugs[slug] = true
+		unique = append(unique, path)
+	}
+	for _, path := range unique {
+		l.loadInto(&posts, &loadErrs, path)
+	}
+
+	if len(loadErrs) > 0 {
+		return posts, loadErrs
+	}
+	return posts, nil
+}
 
-	return posts, err
+// loadInto loads path, appending the post or its failure, and a warning for
+// each link to an unknown post or problem rendering it
+func (l *Loader) loadInto(posts *[]*store.Post, loadErrs *LoadErrors, path string) {
+	post, err := l.LoadFile(path)
+	if err != nil {
+		*loadErrs = append(*loadErrs, newLoadError(path, err))
+		return
+	}
+	if post == nil {
+		return
+	}
+	*posts = append(*posts, post)
+	for _, reason := range l.warnings[path] {
+		*loadErrs = append(*loadErrs, LoadError{Path: path, Reason: reason, Warning: true})
+	}
+	for _, slug := range post.Links {
+		if !l.slugs[slug] {
+			*loadErrs = append(*loadErrs, LoadError{
+				Path:    path,
+				Reason:  fmt.Sprintf("link to unknown post %q", slug),
+				Warning: true,
+			})
+		}
+	}
 }
 
 // LoadFile loads and parses a single markdown file
@@ -73,12 +272,14 @@ func (l *Loader) LoadFile(filePath string) (*store.Post, error) {
 	return l.ParseContent(string(content), filePath)
 }
 
-// ParseContent parses markdown content with front matter
+// ParseContent parses markdown content with front matter, processing
+// external links against the loader's base URL
 func (l *Loader) ParseContent(content, filePath string) (*store.Post, error) {
-	return l.ParseContentWithBaseURL(content, filePath, "")
+	return l.ParseContentWithBaseURL(content, filePath, l.baseURL)
 }
 
-// ParseContentWithBaseURL parses markdown content with front matter and processes external links
+// ParseContentWithBaseURL parses markdown content with front matter and
+// processes external links against baseURL
 func (l *Loader) ParseContentWithBaseURL(content, filePath, baseURL string) (*store.Post, error) {
 	// Split front matter and content
 	frontMatter, markdown, err := l.splitFrontMatter(content)
@@ -89,21 +290,40 @@ func (l *Loader) ParseContentWithBaseURL(content, filePath, baseURL string) (*st
 	// Generate slug from filename
 	slug := l.generateSlug(filePath)
 
-	// Render markdown to HTML with link processing
-	var html string
-	if baseURL != "" {
-		html, err = l.renderer.RenderWithLinkProcessing(markdown, baseURL)
-	} else {
-		html, err = l.renderer.Render(markdown)
+	// Page bundles resolve relative image references against their served path
+	opts := RenderOptions{Slugs: l.slugs, Math: frontMatter.Math}
+	bundleDir := l.bundleDirFor(filePath)
+	if bundleDir != "" {
+		opts.AssetBase = BundleAssetPath(slug)
+		opts.AssetDir = filepath.Dir(filePath)
 	}
+
+	// Render markdown to HTML with link processing
+	doc, err := l.renderer.RenderDocument(markdown, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to render markdown: %w", err)
 	}
+	html := l.postProcess(doc.HTML, frontMatter, baseURL)
+	if l.warnings != nil && len(doc.Warnings) > 0 {
+		l.warnings[filePath] = doc.Warnings
+	}
 
 	// Parse date
 	publishedAt, err := l.parseDate(frontMatter.Date)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse date: %w", err)
+		return nil, &lineError{
+			line: frontMatterKeyLine(content, "date"),
+			err:  fmt.Errorf("failed to parse date: %w", err),
+		}
+	}
+	updatedAt := publishedAt
+	if frontMatter.Updated != "" {
+		if updatedAt, err = l.parseDate(frontMatter.Updated); err != nil {
+			return nil, &lineError{
+				line: frontMatterKeyLine(content, "updated"),
+				err:  fmt.Errorf("failed to parse updated: %w", err),
+			}
+		}
 	}
 
 	post := &store.Post{
@@ -113,14 +333,166 @@ func (l *Loader) ParseContentWithBaseURL(content, filePath, baseURL string) (*st
 		HTML:        html,
 		RawMD:       markdown,
 		PublishedAt: publishedAt,
-		UpdatedAt:   time.Now().Format(time.RFC3339),
+		UpdatedAt:   updatedAt,
 		Draft:       frontMatter.Draft,
-		Tags:        frontMatter.Tags,
+		Section:     l.sectionFor(filePath),
+		BundleDir:   bundleDir,
+		TOC:         doc.TOC,
+		ShowTOC:     frontMatter.TOC,
+		WordCount:   doc.WordCount,
+		ReadingTime: ReadingTime(doc.WordCount),
+		Excerpt:     doc.Excerpt,
+		Series:      strings.TrimSpace(frontMatter.Series),
+		SeriesOrder: frontMatter.SeriesOrder,
+		Tags:        l.taxonomy.CanonicalTags(frontMatter.Tags),
+		TagNames:    l.taxonomy.DisplayNames(frontMatter.Tags),
+		Links:       PostLinks(html, baseURL),
+		Math:        frontMatter.Math,
+		Mermaid:     doc.Mermaid,
+		Code:        doc.Code,
 	}
 
 	return post, nil
 }
 
+// LoadSections loads section metadata for each top-level content directory.
+// A directory's optional _index.md provides its title, summary and intro text;
+// without one the title is derived from the directory name.
+func (l *Loader) LoadSections() ([]*store.Section, error) {
+	entries, err := os.ReadDir(l.contentDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []*store.Section
+	var loadErrs LoadErrors
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		dir := filepath.Join(l.contentDir, entry.Name())
+		if isBundleDir(dir) || !containsMarkdown(dir) {
+			continue
+		}
+
+		section := &store.Section{
+			Name:  entry.Name(),
+			Title: titleFromName(entry.Name()),
+		}
+
+		indexPath := filepath.Join(dir, sectionIndexFile)
+		if raw, err := os.ReadFile(indexPath); err == nil {
+			if err := l.parseSectionIndex(string(raw), section); err != nil {
+				loadErrs = append(loadErrs, newLoadError(indexPath, err))
+			}
+		}
+
+		sections = append(sections, section)
+	}
+
+	if len(loadErrs) > 0 {
+		return sections, loadErrs
+	}
+	return sections, nil
+}
+
+// parseSectionIndex applies the front matter and body of an _index.md to section
+func (l *Loader) parseSectionIndex(content string, section *store.Section) error {
+	frontMatter, markdown, err := l.splitFrontMatter(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse front matter: %w", err)
+	}
+
+	intro, err := l.renderer.Render(markdown)
+	if err != nil {
+		return fmt.Errorf("failed to render markdown: %w", err)
+	}
+
+	if frontMatter.Title != "" {
+		section.Title = frontMatter.Title
+	}
+	section.Summary = frontMatter.Summary
+	section.IntroHTML = l.postProcess(intro, frontMatter, l.baseURL)
+	return nil
+}
+
+// sectionFor returns the top-level content directory containing filePath,
+// or "" for files directly under the content root. A page bundle belongs to
+// the section containing its directory.
+func (l *Loader) sectionFor(filePath string) string {
+	if isBundleIndex(filePath) {
+		filePath = filepath.Dir(filePath)
+	}
+	rel, err := filepath.Rel(l.contentDir, filePath)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) < 2 || parts[0] == ".." {
+		return ""
+	}
+	return parts[0]
+}
+
+// sectionIndexFile holds a section's title and intro text
+const sectionIndexFile = "_index.md"
+
+// bundleDirFor returns a page bundle's directory relative to the content root,
+// or "" when filePath is not a bundle's index.md
+func (l *Loader) bundleDirFor(filePath string) string {
+	if !isBundleIndex(filePath) {
+		return ""
+	}
+	rel, err := filepath.Rel(l.contentDir, filepath.Dir(filePath))
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// isBundleIndex reports whether path is a page bundle's index.md
+func isBundleIndex(path string) bool {
+	return filepath.Base(path) == bundleIndexFile
+}
+
+// isBundleDir reports whether dir is a page bundle, i.e. holds an index.md
+func isBundleDir(dir string) bool {
+	st, err := os.Stat(filepath.Join(dir, bundleIndexFile))
+	return err == nil && !st.IsDir()
+}
+
+// isSectionIndex reports whether path is a section's _index.md
+func isSectionIndex(path string) bool {
+	return strings.EqualFold(filepath.Base(path), sectionIndexFile)
+}
+
+// containsMarkdown reports whether dir holds any markdown file, at any depth
+func containsMarkdown(dir string) bool {
+	found := false
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || found {
+			return nil
+		}
+		if !info.IsDir() && strings.HasSuffix(strings.ToLower(path), ".md") {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// titleFromName turns a directory name such as "deep-dives" into "Deep Dives"
+func titleFromName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' })
+	for i, w := range words {
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = string(unicode.ToTitle(r)) + w[size:]
+	}
+	return strings.Join(words, " ")
+}
+
 // splitFrontMatter separates YAML front matter from markdown content
 func (l *Loader) splitFrontMatter(content string) (*FrontMatter, string, error) {
 	// Check for front matter delimiter
@@ -131,13 +503,13 @@ func (l *Loader) splitFrontMatter(content string) (*FrontMatter, string, error)
 	// Find the closing delimiter
 	parts := strings.SplitN(content[4:], "\n---\n", 2)
 	if len(parts) != 2 {
-		return nil, "", fmt.Errorf("invalid front matter format")
+		return nil, "", &lineError{line: 1, err: fmt.Errorf("invalid front matter format: missing closing ---")}
 	}
 
-	// Parse YAML front matter
+	// Parse YAML front matter (offset by the opening --- line)
 	var fm FrontMatter
 	if err := yaml.Unmarshal([]byte(parts[0]), &fm); err != nil {
-		return nil, "", fmt.Errorf("failed to parse YAML: %w", err)
+		return nil, "", &lineError{line: yamlErrorLine(err, 1), err: fmt.Errorf("failed to parse YAML: %w", err)}
 	}
 
 	return &fm, parts[1], nil
@@ -145,10 +517,14 @@ func (l *Loader) splitFrontMatter(content string) (*FrontMatter, string, error)
 
 // generateSlug creates a URL-friendly slug from filepath
 func (l *Loader) generateSlug(filePath string) string {
-	// Get filename without extension
-	base := filepath.Base(filePath)
-	ext := filepath.Ext(base)
-	slug := strings.TrimSuffix(base, ext)
+	// Get filename without extension; a page bundle is named by its directory
+	var slug string
+	if isBundleIndex(filePath) {
+		slug = filepath.Base(filepath.Dir(filePath))
+	} else {
+		base := filepath.Base(filePath)
+		slug = strings.TrimSuffix(base, filepath.Ext(base))
+	}
 
 	// Remove date prefix if present (e.g., "2025-09-12-")
 	parts := strings.Split(slug, "-")
@@ -163,19 +539,22 @@ func (l *Loader) generateSlug(filePath string) string {
 // parseDate parses date string into RFC3339 format
 func (l *Loader) parseDate(dateStr string) (string, error) {
 	if dateStr == "" {
-		return time.Now().Format(time.RFC3339), nil
+		return time.Now().UTC().Format(time.RFC3339), nil
 	}
 
-	// Try common date formats
+	// Try common date formats. Formats without an offset are read in the
+	// site's zone; everything is stored as UTC so published_at sorts correctly.
 	formats := []string{
 		"2006-01-02",
+		"2006-01-02 15:04",
+		"2006-01-02T15:04:05",
 		"2006-01-02T15:04:05Z07:00",
 		time.RFC3339,
 	}
 
 	for _, format := range formats {
-		if t, err := time.Parse(format, dateStr); err == nil {
-			return t.Format(time.RFC3339), nil
+		if t, err := time.ParseInLocation(format, dateStr, l.location); err == nil {
+			return t.UTC().Format(time.RFC3339), nil
 		}
 	}
 
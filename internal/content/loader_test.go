@@ -1,13 +1,16 @@
 package content
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseFrontMatter(t *testing.T) {
-	loader := NewLoader("./test")
+	loader := NewLoader("./test", "")
 	
 	content := `---
 title: "Test Post"
@@ -54,7 +57,7 @@ This is a **test** markdown post with some ` + "`code`" + ` highlighting.
 }
 
 func TestGenerateSlug(t *testing.T) {
-	loader := NewLoader("./test")
+	loader := NewLoader("./test", "")
 
 	tests := []struct {
 		filePath string
@@ -75,7 +78,7 @@ func TestGenerateSlug(t *testing.T) {
 }
 
 func TestParseDate(t *testing.T) {
-	loader := NewLoader("./test")
+	loader := NewLoader("./test", "")
 
 	tests := []struct {
 		input    string
@@ -104,6 +107,37 @@ func TestParseDate(t *testing.T) {
 	}
 }
 
+func TestParseDateInLocation(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("zone data unavailable: %v", err)
+	}
+
+	loader := NewLoader("./test", "")
+	loader.SetLocation(la)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2025-09-17", "2025-09-17T07:00:00Z"},           // Midnight in the site zone
+		{"2025-09-17 18:30", "2025-09-18T01:30:00Z"},     // Naive time in the site zone
+		{"2025-09-17T10:00:00Z", "2025-09-17T10:00:00Z"}, // Explicit offsets win
+		{"2025-09-17T10:00:00+02:00", "2025-09-17T08:00:00Z"},
+	}
+
+	for _, test := range tests {
+		got, err := loader.parseDate(test.input)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", test.input, err)
+			continue
+		}
+		if got != test.expected {
+			t.Errorf("parseDate(%s) = %s, expected %s", test.input, got, test.expected)
+		}
+	}
+}
+
 func TestLoadAllFromDirectory(t *testing.T) {
 	// Create temporary test directory
 	tempDir := "test_content"
@@ -133,7 +167,7 @@ This is a test.
 	}
 
 	// Test loading
-	loader := NewLoader(tempDir)
+	loader := NewLoader(tempDir, "")
 	posts, err := loader.LoadAll()
 	if err != nil {
 		t.Fatalf("LoadAll failed: %v", err)
@@ -146,4 +180,307 @@ This is a test.
 	if posts[0].Title != "Integration Test" {
 		t.Errorf("Expected title 'Integration Test', got %s", posts[0].Title)
 	}
-}
\ No newline at end of file
+}
+
+func TestLoadAllCollectsErrors(t *testing.T) {
+	tempDir := t.TempDir()
+
+	valid := "---\ntitle: \"Valid\"\ndate: \"2025-09-12\"\n---\n\nBody.\n"
+	badYAML := "---\ntitle: \"Bad\"\ntags: [unclosed\n---\n\nBody.\n"
+	badDate := "---\ntitle: \"Bad date\"\ndate: \"not-a-date\"\n---\n\nBody.\n"
+
+	files := map[string]string{
+		"valid.md":    valid,
+		"bad-yaml.md": badYAML,
+		"bad-date.md": badDate,
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(body), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	loader := NewLoader(tempDir, "")
+	posts, err := loader.LoadAll()
+	if err == nil {
+		t.Fatal("Expected LoadAll to report errors")
+	}
+
+	if len(posts) != 1 || posts[0].Title != "Valid" {
+		t.Fatalf("Expected the valid post to still load, got %d posts", len(posts))
+	}
+
+	loadErrs := AsLoadErrors(err)
+	if len(loadErrs) != 2 {
+		t.Fatalf("Expected 2 load errors, got %d: %v", len(loadErrs), err)
+	}
+
+	byFile := map[string]LoadError{}
+	for _, le := range loadErrs {
+		byFile[filepath.Base(le.Path)] = le
+		if le.Reason == "" {
+			t.Errorf("Expected a reason for %s", le.Path)
+		}
+	}
+
+	if le, ok := byFile["bad-date.md"]; !ok {
+		t.Error("Expected bad-date.md to be reported")
+	} else if le.Line != 3 {
+		t.Errorf("Expected bad-date.md error on line 3, got %d", le.Line)
+	}
+
+	if le, ok := byFile["bad-yaml.md"]; !ok {
+		t.Error("Expected bad-yaml.md to be reported")
+	} else if le.Line == 0 {
+		t.Error("Expected bad-yaml.md error to carry a line number")
+	}
+}
+
+func TestLoadAllReportsDuplicateSlugs(t *testing.T) {
+	tempDir := t.TempDir()
+	post := "---\ntitle: \"%s\"\ndate: \"2025-09-12\"\n---\n\nBody.\n"
+	for _, rel := range []string{"essays/foo.md", "notes/2025-09-12-foo.md"} {
+		path := filepath.Join(tempDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", rel, err)
+		}
+		if err := os.WriteFile(path, []byte(fmt.Sprintf(post, rel)), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", rel, err)
+		}
+	}
+
+	posts, err := NewLoader(tempDir, "").LoadAll()
+	if len(posts) != 1 || posts[0].Title != "essays/foo.md" {
+		t.Fatalf("Expected only the first foo to load, got %d posts", len(posts))
+	}
+	loadErrs := AsLoadErrors(err)
+	if len(loadErrs) != 1 || loadErrs[0].Warning {
+		t.Fatalf("Expected one failed file, got %v", err)
+	}
+	le := loadErrs[0]
+	if !strings.HasSuffix(le.Path, filepath.Join("notes", "2025-09-12-foo.md")) || !strings.Contains(le.Reason, filepath.Join("essays", "foo.md")) {
+		t.Errorf("Expected the error to name both paths, got %v", le)
+	}
+}
+
+func TestTitleFromName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"til":           "Til",
+		"side_projects": "Side Projects",
+		"études-notes":  "Études Notes",
+	} {
+		if got := titleFromName(name); got != expected {
+			t.Errorf("titleFromName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestLoadErrorsMessage(t *testing.T) {
+	failed := LoadError{Path: "a.md", Reason: "bad date"}
+	warning := LoadError{Path: "b.md", Reason: "broken link", Warning: true}
+
+	testCases := []struct {
+		errs     LoadErrors
+		expected string
+	}{
+		{LoadErrors{failed, failed}, "2 content files failed to load:"},
+		{LoadErrors{warning, warning}, "2 content warnings:"},
+		{LoadErrors{failed, warning, warning}, "1 content file failed to load, 2 warnings:"},
+	}
+	for _, tc := range testCases {
+		if got := tc.errs.Error(); !strings.HasPrefix(got, tc.expected+"\n") {
+			t.Errorf("Expected message starting %q, got %q", tc.expected, got)
+		}
+	}
+}
+
+func TestLoadSections(t *testing.T) {
+	tempDir := t.TempDir()
+
+	write := func(rel, body string) {
+		path := filepath.Join(tempDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", rel, err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", rel, err)
+		}
+	}
+
+	post := "---\ntitle: \"%s\"\ndate: \"2025-09-12\"\n---\n\nBody.\n"
+	write("root-post.md", fmt.Sprintf(post, "Root"))
+	write("projects/2025-09-12-notebook.md", fmt.Sprintf(post, "Notebook"))
+	write("projects/_index.md", "---\ntitle: \"Things I Built\"\nsummary: \"Side projects\"\n---\n\nA list of **projects**.\n")
+	write("til/2025-09-13-go-embed.md", fmt.Sprintf(post, "Go embed"))
+
+	loader := NewLoader(tempDir, "")
+	posts, err := loader.LoadAll()
+	if err != nil {
+		t.Fatalf("LoadAll failed: %v", err)
+	}
+	if len(posts) != 3 {
+		t.Fatalf("Expected 3 posts (_index.md excluded), got %d", len(posts))
+	}
+
+	bySlug := map[string]string{}
+	for _, p := range posts {
+		bySlug[p.Slug] = p.Section
+	}
+	if bySlug["root-post"] != "" || bySlug["notebook"] != "projects" || bySlug["go-embed"] != "til" {
+		t.Errorf("Unexpected sections by slug: %v", bySlug)
+	}
+
+	sections, err := loader.LoadSections()
+	if err != nil {
+		t.Fatalf("LoadSections failed: %v", err)
+	}
+	if len(sections) != 2 {
+		t.Fatalf("Expected 2 sections, got %d", len(sections))
+	}
+
+	projects, til := sections[0], sections[1]
+	if projects.Name != "projects" || projects.Title != "Things I Built" || projects.Summary != "Side projects" {
+		t.Errorf("Expected _index.md metadata for projects, got %+v", projects)
+	}
+	if !strings.Contains(projects.IntroHTML, "<strong>projects</strong>") {
+		t.Errorf("Expected rendered intro, got %q", projects.IntroHTML)
+	}
+	if til.Name != "til" || til.Title != "Til" {
+		t.Errorf("Expected title derived from directory name, got %+v", til)
+	}
+}
+
+func TestLoadSiteReportsUnreadableSectionsAsWarning(t *testing.T) {
+	loader := NewLoader(filepath.Join(t.TempDir(), "missing"), "")
+	site, err := loader.LoadSite()
+	if site == nil || site.Taxonomy == nil {
+		t.Fatalf("Expected a site with its taxonomy, got %+v (%v)", site, err)
+	}
+
+	loadErrs := AsLoadErrors(err)
+	if loadErrs == nil {
+		t.Fatalf("Expected LoadErrors, got %v", err)
+	}
+	var sectionWarning bool
+	for _, le := range loadErrs {
+		if le.Warning && strings.Contains(le.Reason, "failed to load sections") {
+			sectionWarning = true
+		}
+	}
+	if !sectionWarning {
+		t.Errorf("Expected the sections failure as a warning, got %v", loadErrs)
+	}
+}
+
+func TestParseTOCFrontMatter(t *testing.T) {
+	loader := NewLoader("./test", "")
+
+	content := "---\ntitle: \"Long Post\"\ndate: \"2025-09-15\"\ntoc: true\n---\n\n## First\n\n## Second\n"
+	post, err := loader.ParseContent(content, "long-post.md")
+	if err != nil {
+		t.Fatalf("ParseContent failed: %v", err)
+	}
+	if !post.ShowTOC {
+		t.Error("Expected toc: true to set ShowTOC")
+	}
+	if len(post.TOC) != 2 || post.TOC[1].ID != "second" {
+		t.Errorf("Expected two TOC entries, got %+v", post.TOC)
+	}
+}
+
+func TestParseUpdatedFrontMatter(t *testing.T) {
+	loader := NewLoader("./test", "")
+
+	post, err := loader.ParseContent("---\ntitle: \"Revised\"\ndate: \"2025-09-15\"\nupdated: \"2025-10-01\"\n---\n\nBody.\n", "revised.md")
+	if err != nil {
+		t.Fatalf("ParseContent failed: %v", err)
+	}
+	if post.UpdatedAt != "2025-10-01T00:00:00Z" {
+		t.Errorf("Expected updated date from front matter, got %s", post.UpdatedAt)
+	}
+
+	post, err = loader.ParseContent("---\ntitle: \"Original\"\ndate: \"2025-09-15\"\n---\n\nBody.\n", "original.md")
+	if err != nil {
+		t.Fatalf("ParseContent failed: %v", err)
+	}
+	if post.UpdatedAt != post.PublishedAt {
+		t.Errorf("Expected updated to default to the published date, got %s", post.UpdatedAt)
+	}
+
+	_, err = loader.ParseContent("---\ntitle: \"Bad\"\ndate: \"2025-09-15\"\nupdated: \"soon\"\n---\n\nBody.\n", "bad.md")
+	if err == nil || !strings.Contains(err.Error(), "failed to parse updated") {
+		t.Errorf("Expected an updated parse error, got %v", err)
+	}
+}
+
+func TestParseComputesReadingStats(t *testing.T) {
+	loader := NewLoader("./test", "")
+
+	body := strings.Repeat("word ", 450)
+	content := "---\ntitle: \"No Summary\"\ndate: \"2025-09-15\"\n---\n\n" + body + "\n"
+	post, err := loader.ParseContent(content, "no-summary.md")
+	if err != nil {
+		t.Fatalf("ParseContent failed: %v", err)
+	}
+	if post.WordCount != 450 {
+		t.Errorf("Expected 450 words, got %d", post.WordCount)
+	}
+	if post.ReadingTime != 3 {
+		t.Errorf("Expected 3 minute reading time, got %d", post.ReadingTime)
+	}
+	if post.Summary != "" || !strings.HasPrefix(post.Description(), "word word") {
+		t.Errorf("Expected description to fall back to the excerpt, got %q", post.Description())
+	}
+}
+
+func TestParseSeriesFrontMatter(t *testing.T) {
+	loader := NewLoader("./test", "")
+
+	content := "---\ntitle: \"Part Two\"\ndate: \"2025-09-15\"\nseries: \"Building Notebook\"\nseries_order: 2\n---\n\nBody\n"
+	post, err := loader.ParseContent(content, "part-two.md")
+	if err != nil {
+		t.Fatalf("ParseContent failed: %v", err)
+	}
+	if post.Series != "Building Notebook" || post.SeriesOrder != 2 {
+		t.Errorf("Expected series Building Notebook part 2, got %q part %d", post.Series, post.SeriesOrder)
+	}
+}
+
+func TestLoadTaxonomyResolvesAliases(t *testing.T) {
+	dir := t.TempDir()
+	taxonomyYAML := "aliases:\n  golang: go\n"
+	if err := os.WriteFile(filepath.Join(dir, "taxonomy.yaml"), []byte(taxonomyYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewLoader(dir, "")
+	if _, err := loader.LoadTaxonomy(); err != nil {
+		t.Fatalf("LoadTaxonomy failed: %v", err)
+	}
+
+	content := "---\ntitle: \"Aliased\"\ndate: \"2025-09-15\"\ntags: [\"golang\", \"go\", \"sqlite\"]\n---\n\nBody\n"
+	post, err := loader.ParseContent(content, filepath.Join(dir, "aliased.md"))
+	if err != nil {
+		t.Fatalf("ParseContent failed: %v", err)
+	}
+	if strings.Join(post.Tags, ",") != "go,sqlite" {
+		t.Errorf("Expected alias resolved to go, got %v", post.Tags)
+	}
+}
+
+func TestLoadTaxonomyReportsInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "taxonomy.yaml")
+	if err := os.WriteFile(path, []byte("categories:\n  - name: a\n    match:\n      regex: [\"(\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tax, err := NewLoader(dir, "").LoadTaxonomy()
+	loadErrs := AsLoadErrors(err)
+	if len(loadErrs) != 1 || loadErrs[0].Path != path {
+		t.Fatalf("Expected one load error for %s, got %v", path, err)
+	}
+	if tax == nil || tax.Categorize("go") != "technical" {
+		t.Error("Expected the built-in taxonomy as a fallback")
+	}
+}
@@ -0,0 +1,256 @@
+package content
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// mathKey is set when the document opted into math with math: true.
+// Without it "$" is ordinary text, so prices in other posts stay as written.
+var mathKey = parser.NewContextKey()
+
+// Math is rendered as TeX between \( \) or \[ \] delimiters inside elements
+// classed "math math-inline" or "math math-display". /static/math.js typesets
+// them with KaTeX; without script the TeX source stays readable.
+
+// kindMathInline and kindMathBlock are the AST node kinds of math
+var (
+	kindMathInline = ast.NewNodeKind("MathInline")
+	kindMathBlock  = ast.NewNodeKind("MathBlock")
+)
+
+// mathInline is $tex$, or $$tex$$ within a paragraph when Display is set
+type mathInline struct {
+	ast.BaseInline
+	TeX     text.Segment
+	Display bool
+}
+
+// Kind implements ast.Node
+func (n *mathInline) Kind() ast.NodeKind {
+	return kindMathInline
+}
+
+// Dump implements ast.Node
+func (n *mathInline) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"TeX": string(n.TeX.Value(source))}, nil)
+}
+
+// mathBlock is a display equation between lines starting and ending with $$.
+// Its lines hold the TeX.
+type mathBlock struct {
+	ast.BaseBlock
+	closed bool // The closing $$ has been read
+}
+
+// Kind implements ast.Node
+func (n *mathBlock) Kind() ast.NodeKind {
+	return kindMathBlock
+}
+
+// IsRaw implements ast.Node; TeX is not parsed as markdown
+func (n *mathBlock) IsRaw() bool {
+	return true
+}
+
+// Dump implements ast.Node
+func (n *mathBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// mathExtension is a goldmark extension for $inline$ and $$display$$ TeX
+type mathExtension struct{}
+
+// Extend implements goldmark.Extender
+func (e *mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(util.Prioritized(&mathBlockParser{}, 750)),
+		parser.WithInlineParsers(util.Prioritized(&mathInlineParser{}, 150)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&mathRenderer{}, 500),
+	))
+}
+
+// mathBlockParser parses display math on lines of its own:
+//
+//	$$
+//	e^{i\pi} + 1 = 0
+//	$$
+//
+// or on a single line, $$ e^{i\pi} + 1 = 0 $$
+type mathBlockParser struct{}
+
+var mathDelimiter = []byte("$$")
+
+// Trigger implements parser.BlockParser
+func (p *mathBlockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Open implements parser.BlockParser
+func (p *mathBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	if pc.Get(mathKey) == nil {
+		return nil, parser.NoChildren
+	}
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 || !bytes.HasPrefix(line[pos:], mathDelimiter) {
+		return nil, parser.NoChildren
+	}
+
+	rest := util.TrimRightSpace(line[pos+2:])
+	node := &mathBlock{}
+	switch {
+	case len(util.TrimLeftSpace(rest)) == 0:
+		// Opening line of a multi-line block
+	case len(rest) >= 2 && bytes.HasSuffix(rest, mathDelimiter):
+		// $$ tex $$ on one line
+		start := segment.Start + pos + 2
+		node.Lines().Append(text.NewSegment(start, start+len(rest)-2))
+		node.closed = true
+	default:
+		// $$tex$$ followed by more text is inline display math
+		return nil, parser.NoChildren
+	}
+	advanceLine(reader, line, segment)
+	return node, parser.NoChildren
+}
+
+// Continue implements parser.BlockParser
+func (p *mathBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	block := node.(*mathBlock)
+	if block.closed {
+		return parser.Close
+	}
+
+	line, segment := reader.PeekLine()
+	trimmed := util.TrimRightSpace(line)
+	if bytes.HasSuffix(trimmed, mathDelimiter) {
+		if tex := len(trimmed) - 2; len(util.TrimLeftSpace(trimmed[:tex])) > 0 {
+			node.Lines().Append(text.NewSegment(segment.Start, segment.Start+tex))
+		}
+		advanceLine(reader, line, segment)
+		return parser.Close
+	}
+
+	node.Lines().Append(segment)
+	advanceLine(reader, line, segment)
+	return parser.Continue | parser.NoChildren
+}
+
+// Close implements parser.BlockParser
+func (p *mathBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser
+func (p *mathBlockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser
+func (p *mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// advanceLine moves reader past line, leaving its newline for the parser
+func advanceLine(reader text.Reader, line []byte, segment text.Segment) {
+	n := segment.Len()
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		n--
+	}
+	reader.Advance(n)
+}
+
+// mathInlineParser parses $tex$ and $$tex$$ within a line. As in Pandoc, the
+// opening $ must not be followed by a space and the closing $ must not follow
+// a space or precede a digit, so "$5 and $10" stays text.
+type mathInlineParser struct{}
+
+// Trigger implements parser.InlineParser
+func (p *mathInlineParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Parse implements parser.InlineParser
+func (p *mathInlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	if pc.Get(mathKey) == nil {
+		return nil
+	}
+	line, segment := block.PeekLine()
+
+	if bytes.HasPrefix(line, mathDelimiter) {
+		end := bytes.Index(line[2:], mathDelimiter)
+		if end <= 0 {
+			return nil
+		}
+		block.Advance(2 + end + 2)
+		return &mathInline{TeX: text.NewSegment(segment.Start+2, segment.Start+2+end), Display: true}
+	}
+
+	if len(line) < 3 || util.IsSpace(line[1]) {
+		return nil
+	}
+	for i := 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++ // \$ is part of the TeX
+		case '\n':
+			return nil
+		case '$':
+			if util.IsSpace(line[i-1]) || (i+1 < len(line) && line[i+1] >= '0' && line[i+1] <= '9') {
+				continue
+			}
+			block.Advance(i + 1)
+			return &mathInline{TeX: text.NewSegment(segment.Start+1, segment.Start+i)}
+		}
+	}
+	return nil
+}
+
+// mathRenderer writes math with the delimiters KaTeX's auto-render and
+// MathJax recognise
+type mathRenderer struct{}
+
+// RegisterFuncs implements renderer.NodeRenderer
+func (r *mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMathInline, r.renderInline)
+	reg.Register(kindMathBlock, r.renderBlock)
+}
+
+func (r *mathRenderer) renderInline(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	m := n.(*mathInline)
+	tex := util.EscapeHTML(m.TeX.Value(source))
+	if m.Display {
+		_, _ = w.WriteString(`<span class="math math-display">\[`)
+		_, _ = w.Write(tex)
+		_, _ = w.WriteString(`\]</span>`)
+	} else {
+		_, _ = w.WriteString(`<span class="math math-inline">\(`)
+		_, _ = w.Write(tex)
+		_, _ = w.WriteString(`\)</span>`)
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *mathRenderer) renderBlock(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	_, _ = w.WriteString(`<div class="math math-display">\[`)
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		_, _ = w.Write(util.EscapeHTML(seg.Value(source)))
+	}
+	_, _ = w.WriteString("\\]</div>\n")
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,107 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMath(t *testing.T) {
+	renderer := NewRenderer()
+
+	testCases := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{
+			name:     "inline",
+			markdown: "Euler: $e^{i\\pi} + 1 = 0$.",
+			expected: `<p>Euler: <span class="math math-inline">\(e^{i\pi} + 1 = 0\)</span>.</p>`,
+		},
+		{
+			name:     "tex is not markdown",
+			markdown: "$a_1 * b_2 * c_3$",
+			expected: `<p><span class="math math-inline">\(a_1 * b_2 * c_3\)</span></p>`,
+		},
+		{
+			name:     "tex is escaped",
+			markdown: "$a < b$",
+			expected: `<p><span class="math math-inline">\(a &lt; b\)</span></p>`,
+		},
+		{
+			name:     "prices are not math",
+			markdown: "It costs $5 or $10.",
+			expected: `<p>It costs $5 or $10.</p>`,
+		},
+		{
+			name:     "escaped dollar",
+			markdown: "\\$x$ stays",
+			expected: `<p>$x$ stays</p>`,
+		},
+		{
+			name:     "display within a paragraph",
+			markdown: "so $$\\sum_i x_i$$ holds",
+			expected: `<p>so <span class="math math-display">\[\sum_i x_i\]</span> holds</p>`,
+		},
+		{
+			name:     "display block",
+			markdown: "$$\n\\frac{a}{b}\n= c\n$$\n\nAfter.",
+			expected: "<div class=\"math math-display\">\\[\\frac{a}{b}\n= c\n\\]</div>\n<p>After.</p>",
+		},
+		{
+			name:     "single line display block",
+			markdown: "$$ x^2 $$\n",
+			expected: `<div class="math math-display">\[ x^2 \]</div>`,
+		},
+		{
+			name:     "code keeps dollars",
+			markdown: "`$x$`",
+			expected: `<p><code>$x$</code></p>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := renderer.RenderDocument(tc.markdown, RenderOptions{Math: true})
+			if err != nil {
+				t.Fatalf("RenderDocument failed: %v", err)
+			}
+			if got := strings.TrimSpace(doc.HTML); got != tc.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestRenderMathDisabled(t *testing.T) {
+	renderer := NewRenderer()
+
+	html, err := renderer.Render("$x$ and\n\n$$\ny\n$$\n")
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if strings.Contains(html, "math") {
+		t.Errorf("Expected no math without math: true, got %s", html)
+	}
+}
+
+func TestRenderMathWithCodeAndFootnotes(t *testing.T) {
+	renderer := NewRenderer()
+
+	markdown := "Note $x$.[^1]\n\n```go\ns := \"$a$\"\n```\n\n[^1]: Where $x > 0$.\n"
+	doc, err := renderer.RenderDocument(markdown, RenderOptions{Math: true})
+	if err != nil {
+		t.Fatalf("RenderDocument failed: %v", err)
+	}
+	html := DefaultSanitizer().Sanitize(doc.HTML)
+
+	if !strings.Contains(html, `<pre class="chroma">`) || !strings.Contains(html, "$a$") {
+		t.Errorf("Expected the code block highlighted with its dollars intact, got %s", html)
+	}
+	if !strings.Contains(html, `class="footnote-ref"`) || !strings.Contains(html, `\(x &gt; 0\)`) {
+		t.Errorf("Expected footnotes with math in them, got %s", html)
+	}
+	if doc.WordCount != 2 { // "Note" and "Where"
+		t.Errorf("Expected math left out of the word count, got %d words", doc.WordCount)
+	}
+}
@@ -4,35 +4,86 @@ import (
 	"bytes"
 
 	"github.com/alecthomas/chroma/v2/formatters/html"
-	"github.com/alecthomas/chroma/v2/styles"
 	"github.com/yuin/goldmark"
 	highlighting "github.com/yuin/goldmark-highlighting/v2"
 	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
 	htmlrenderer "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	"notebook.oceanheart.ai/internal/imaging"
+	"notebook.oceanheart.ai/internal/store"
 )
 
 // Renderer handles markdown to HTML conversion with syntax highlighting
 type Renderer struct {
-	md goldmark.Markdown
+	md       goldmark.Markdown
+	diagrams map[string]DiagramRenderer // Fence language to diagram renderer
+}
+
+// RenderOptions carries per-document settings for a single render
+type RenderOptions struct {
+	// AssetBase is the URL prefix relative image references resolve against,
+	// e.g. "/p/notebook" for a page bundle. Empty leaves references untouched.
+	AssetBase string
+
+	// AssetDir is the directory AssetBase is served from, used to read image
+	// dimensions for width/height and srcset
+	AssetDir string
+
+	// Slugs is the set of loaded posts wiki-links resolve against. Nil
+	// resolves every link, for rendering a post on its own.
+	Slugs map[string]bool
+
+	// Math enables $inline$ and $$display$$ TeX (math: true front matter)
+	Math bool
+}
+
+// Document is the result of rendering a markdown document
+type Document struct {
+	HTML      string
+	TOC       []store.Heading // Nested h2-h4 headings with their anchor IDs
+	WordCount int             // Prose words, excluding code blocks
+	Excerpt   string          // First paragraph as plain text
+	Warnings  []string        // Problems that did not stop the render, e.g. a failed diagram
+	Mermaid   bool            // A diagram was left to mermaid.js in the browser
+	Code      bool            // Has a highlighted code block, for the copy buttons
 }
 
 // NewRenderer creates a new markdown renderer with syntax highlighting
 func NewRenderer() *Renderer {
 	// Configure syntax highlighting
 	highlighter := highlighting.NewHighlighting(
-		highlighting.WithStyle("github"),
+		highlighting.WithStyle(DefaultHighlightStyle),
 		highlighting.WithFormatOptions(
 			html.WithLineNumbers(true),
 			html.WithClasses(true),
 		),
+		highlighting.WithCodeBlockOptions(codeBlockOptions), // start, hl_lines
+		highlighting.WithWrapperRenderer(codeBlockWrapper),  // title
 	)
 
+	diagramRenderers := DefaultDiagrams()
+
 	// Create goldmark instance with extensions
 	md := goldmark.New(
 		goldmark.WithExtensions(
 			extension.GFM,        // GitHub Flavored Markdown
 			extension.Footnote,   // Footnote support
 			highlighter,          // Syntax highlighting
+			&responsiveImages{widths: imaging.DefaultWidths}, // Lazy images with srcset
+			&wikiLinks{},         // [[slug]] links between posts
+			&mathExtension{},     // $TeX$ when RenderOptions.Math is set
+			&diagrams{renderers: diagramRenderers}, // ```mermaid and ```dot fences
+			&callouts{},                            // > [!NOTE] and :::note callouts
+		),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(), // id="..." on every heading
+			parser.WithASTTransformers(
+				util.Prioritized(&bundleAssetTransformer{}, 100),
+				util.Prioritized(&statsTransformer{}, 250), // Before anchors add "#"
+				util.Prioritized(&headingTransformer{}, 300),
+			),
 		),
 		goldmark.WithRendererOptions(
 			htmlrenderer.WithHardWraps(),
@@ -41,16 +92,72 @@ func NewRenderer() *Renderer {
 		),
 	)
 
-	return &Renderer{md: md}
+	return &Renderer{md: md, diagrams: diagramRenderers}
+}
+
+// SetDiagramRenderer renders fences tagged lang with d instead of
+// highlighting them. A nil d highlights lang as code again. Call it before
+// rendering; it is not safe to use concurrently with Render.
+func (r *Renderer) SetDiagramRenderer(lang string, d DiagramRenderer) {
+	if d == nil {
+		delete(r.diagrams, lang)
+		return
+	}
+	r.diagrams[lang] = d
 }
 
 // Render converts markdown to HTML
 func (r *Renderer) Render(markdown string) (string, error) {
-	var buf bytes.Buffer
-	if err := r.md.Convert([]byte(markdown), &buf); err != nil {
+	return r.RenderWithOptions(markdown, RenderOptions{})
+}
+
+// RenderWithOptions converts markdown to HTML using per-document options
+func (r *Renderer) RenderWithOptions(markdown string, opts RenderOptions) (string, error) {
+	doc, err := r.RenderDocument(markdown, opts)
+	if err != nil {
 		return "", err
 	}
-	return buf.String(), nil
+	return doc.HTML, nil
+}
+
+// RenderDocument converts markdown to HTML and returns the metadata collected
+// while parsing, such as the table of contents
+func (r *Renderer) RenderDocument(markdown string, opts RenderOptions) (*Document, error) {
+	ctx := parser.NewContext()
+	if opts.AssetBase != "" {
+		ctx.Set(assetBaseKey, opts.AssetBase)
+	}
+	if opts.AssetDir != "" {
+		ctx.Set(assetDirKey, opts.AssetDir)
+	}
+	if opts.Slugs != nil {
+		ctx.Set(knownSlugsKey, opts.Slugs)
+	}
+	if opts.Math {
+		ctx.Set(mathKey, true)
+	}
+
+	source := []byte(markdown)
+	root := r.md.Parser().Parse(text.NewReader(source), parser.WithContext(ctx))
+
+	var buf bytes.Buffer
+	if err := r.md.Renderer().Render(&buf, source, root); err != nil {
+		return nil, err
+	}
+
+	entries, _ := ctx.Get(headingsKey).([]tocEntry)
+	stats, _ := ctx.Get(statsKey).(docStats)
+	warnings, _ := ctx.Get(diagramWarningsKey).([]string)
+	mermaid, _ := ctx.Get(mermaidKey).(bool)
+	return &Document{
+		HTML:      buf.String(),
+		TOC:       buildTOC(entries),
+		WordCount: stats.words,
+		Excerpt:   stats.excerpt,
+		Warnings:  warnings,
+		Mermaid:   mermaid,
+		Code:      stats.code,
+	}, nil
 }
 
 // RenderWithLinkProcessing converts markdown to HTML and processes external links
@@ -66,17 +173,8 @@ func (r *Renderer) RenderWithLinkProcessing(markdown string, baseURL string) (st
 	return processedHTML, nil
 }
 
-// GetStyle returns CSS for syntax highlighting
+// GetStyle returns CSS for syntax highlighting in the default style; see
+// HighlightCSS for other styles and a dark palette
 func (r *Renderer) GetStyle() (string, error) {
-	style := styles.Get("github")
-	if style == nil {
-		style = styles.Fallback
-	}
-
-	formatter := html.New(html.WithClasses(true))
-	var buf bytes.Buffer
-	if err := formatter.WriteCSS(&buf, style); err != nil {
-		return "", err
-	}
-	return buf.String(), nil
+	return HighlightCSS(DefaultHighlightStyle, "")
 }
\ No newline at end of file
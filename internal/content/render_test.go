@@ -1,6 +1,7 @@
 package content
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -26,7 +27,7 @@ And a [link](https://example.com).
 
 	// Check for basic HTML elements
 	expected := []string{
-		"<h1>Test Heading</h1>",
+		`<h1 id="test-heading">Test Heading`, // Auto heading IDs for anchors
 		"<strong>bold</strong>",
 		"<em>italic</em>",
 		"<code>code</code>",
@@ -62,6 +63,124 @@ func TestCodeHighlighting(t *testing.T) {
 	}
 }
 
+func TestCodeHighlightLines(t *testing.T) {
+	renderer := NewRenderer()
+
+	html, err := renderer.Render("```go {hl_lines=[2,3]}\na := 1\nb := 2\nc := 3\nd := 4\n```")
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if n := strings.Count(html, `<span class="line hl">`); n != 2 {
+		t.Errorf("Expected 2 highlighted lines, got %d in %s", n, html)
+	}
+}
+
+func TestCodeFenceAttributes(t *testing.T) {
+	renderer := NewRenderer()
+
+	testCases := []struct {
+		name     string
+		markdown string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "title caption without line numbers",
+			markdown: "```go {title=\"main.go\" linenos=false}\nx := 1\n```",
+			contains: []string{"<figure class=\"code\">\n<figcaption class=\"code-title\">main.go</figcaption>\n<pre class=\"chroma\">", "</pre>\n</figure>"},
+			excludes: []string{`class="ln"`},
+		},
+		{
+			name:     "start numbers lines and hl_lines counts from the top",
+			markdown: "```go {hl_lines=\"2-3\" start=10}\na := 1\nb := 2\nc := 3\nd := 4\n```",
+			contains: []string{`<span class="ln">10</span>`, `<span class="line hl"><span class="ln">11</span>`, `<span class="line hl"><span class="ln">12</span>`, `<span class="line"><span class="ln">13</span>`},
+		},
+		{
+			name:     "hl_lines list with start",
+			markdown: "```go {hl_lines=[1, \"3\"] start=5}\na\nb\nc\n```",
+			contains: []string{`<span class="line hl"><span class="ln">5</span>`, `<span class="line"><span class="ln">6</span>`, `<span class="line hl"><span class="ln">7</span>`},
+		},
+		{
+			name:     "title on a language chroma does not know",
+			markdown: "```nosuchlang {title=\"a<b>\"}\nx < y\n```",
+			contains: []string{"<figcaption class=\"code-title\">a&lt;b&gt;</figcaption>\n<pre><code class=\"language-nosuchlang\">x &lt; y\n</code></pre>\n</figure>"},
+		},
+		{
+			name:     "plain fence unchanged",
+			markdown: "```\nplain\n```",
+			contains: []string{"<pre><code>plain\n</code></pre>"},
+			excludes: []string{"<figure"},
+		},
+		{
+			name:     "line numbers stay on by default",
+			markdown: "```go\nx\n```",
+			contains: []string{`<span class="ln">1</span>`},
+			excludes: []string{"<figure"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			html, err := renderer.Render(tc.markdown)
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			for _, want := range tc.contains {
+				if !strings.Contains(html, want) {
+					t.Errorf("Expected %q in:\n%s", want, html)
+				}
+			}
+			for _, unwanted := range tc.excludes {
+				if strings.Contains(html, unwanted) {
+					t.Errorf("Expected no %q in:\n%s", unwanted, html)
+				}
+			}
+		})
+	}
+}
+
+func TestLineRanges(t *testing.T) {
+	testCases := []struct {
+		value    interface{}
+		expected [][2]int
+	}{
+		{float64(4), [][2]int{{4, 4}}},
+		{[]byte("3-5"), [][2]int{{3, 5}}},
+		{[]byte("2, 4-6 9"), [][2]int{{2, 2}, {4, 6}, {9, 9}}},
+		{[]interface{}{float64(1), []byte("3-4")}, [][2]int{{1, 1}, {3, 4}}},
+		{[]byte("x, 5-2, 7"), [][2]int{{7, 7}}},
+		{true, nil},
+	}
+
+	for _, tc := range testCases {
+		if got := lineRanges(tc.value); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("lineRanges(%v): expected %v, got %v", tc.value, tc.expected, got)
+		}
+	}
+}
+
+func TestHighlightCSS(t *testing.T) {
+	light, err := HighlightCSS("", "")
+	if err != nil {
+		t.Fatalf("HighlightCSS failed: %v", err)
+	}
+	if strings.Contains(light, "@media") || !strings.Contains(light, ".chroma .hl") {
+		t.Errorf("Expected an unconditional default palette with highlighted lines, got %s", light)
+	}
+
+	both, err := HighlightCSS("github", "github-dark")
+	if err != nil {
+		t.Fatalf("HighlightCSS failed: %v", err)
+	}
+	if !strings.HasPrefix(both, "@media not all and (prefers-color-scheme: dark) {") || !strings.Contains(both, "@media (prefers-color-scheme: dark) {") {
+		t.Errorf("Expected light and dark palettes under prefers-color-scheme, got %s", both)
+	}
+
+	if _, err := HighlightCSS("github", "no-such-style"); err == nil {
+		t.Error("Expected an error for an unknown style")
+	}
+}
+
 func TestGitHubFlavoredMarkdown(t *testing.T) {
 	renderer := NewRenderer()
 
@@ -93,4 +212,50 @@ func TestGetStyle(t *testing.T) {
 	if !strings.Contains(css, "chroma") {
 		t.Error("Expected chroma-related CSS classes")
 	}
-}
\ No newline at end of file
+}
+func TestHeadingAnchorsAndTOC(t *testing.T) {
+	renderer := NewRenderer()
+
+	markdown := `# Title
+
+## Setup
+
+### Install ` + "`go`" + `
+
+### Configure
+
+## Usage
+
+#### Deep dive
+
+## Setup
+`
+
+	doc, err := renderer.RenderDocument(markdown, RenderOptions{})
+	if err != nil {
+		t.Fatalf("RenderDocument failed: %v", err)
+	}
+
+	// Headings get IDs and a hover permalink
+	if !strings.Contains(doc.HTML, `<h2 id="setup">Setup <a href="#setup" title="Permalink to this section" class="anchor">#</a></h2>`) {
+		t.Errorf("Expected heading anchor, got %s", doc.HTML)
+	}
+
+	// h1 is the post title and stays out of the TOC; h2-h4 nest
+	if len(doc.TOC) != 3 {
+		t.Fatalf("Expected 3 top-level TOC entries, got %d: %+v", len(doc.TOC), doc.TOC)
+	}
+	setup := doc.TOC[0]
+	if setup.ID != "setup" || len(setup.Children) != 2 {
+		t.Errorf("Expected setup with 2 children, got %+v", setup)
+	}
+	if setup.Children[0].Text != "Install go" {
+		t.Errorf("Expected inline code text in TOC, got %q", setup.Children[0].Text)
+	}
+	if usage := doc.TOC[1]; len(usage.Children) != 1 || usage.Children[0].Level != 4 {
+		t.Errorf("Expected h4 to nest under the preceding h2, got %+v", usage)
+	}
+	if doc.TOC[2].ID != "setup-1" {
+		t.Errorf("Expected duplicate heading to get a unique ID, got %s", doc.TOC[2].ID)
+	}
+}
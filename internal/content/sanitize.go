@@ -0,0 +1,289 @@
+package content
+
+import (
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// Sanitizer strips rendered HTML down to an allowlist of elements and
+// attributes, so raw HTML in markdown cannot run script in a reader's
+// browser. Disallowed elements are removed but their text is kept, except
+// for elements like script whose content is dropped too. The policy is
+// compiled on first use, so set the fields before calling Sanitize.
+type Sanitizer struct {
+	// Elements maps allowed element names to the attributes allowed on them
+	// in addition to GlobalAttributes
+	Elements map[string][]string
+
+	// GlobalAttributes are allowed on every allowed element
+	GlobalAttributes []string
+
+	// URLSchemes are the schemes allowed in href, src, srcset and cite;
+	// relative URLs are always allowed
+	URLSchemes []string
+
+	// DropContent lists elements removed together with everything inside them
+	DropContent []string
+
+	once    sync.Once
+	allowed map[string]map[string]bool // Element to its allowed attributes, globals included
+	drop    map[string]bool
+}
+
+// svgAttributes are the presentation and geometry attributes kept on inline
+// SVG diagrams. Event handlers and references to other documents are not.
+// The tokenizer lowercases names, which browsers restore (viewbox to viewBox)
+// when parsing SVG.
+var svgAttributes = []string{
+	"xmlns", "viewbox", "width", "height", "preserveaspectratio", "transform",
+	"x", "y", "x1", "y1", "x2", "y2", "cx", "cy", "r", "rx", "ry", "dx", "dy",
+	"d", "points", "fill", "fill-opacity", "stroke", "stroke-width",
+	"stroke-opacity", "stroke-dasharray", "stroke-linecap", "stroke-linejoin",
+	"opacity", "font-family", "font-size", "font-weight", "font-style",
+	"text-anchor", "dominant-baseline", "marker-start", "marker-end",
+	"markerwidth", "markerheight", "refx", "refy", "orient",
+}
+
+// DefaultSanitizer returns the policy applied to rendered markdown. It keeps
+// everything goldmark, GFM, footnotes, chroma highlighting and the heading
+// and image transformers produce, inline SVG from diagram renderers, plus
+// common inline HTML.
+func DefaultSanitizer() *Sanitizer {
+	return &Sanitizer{
+		Elements: map[string][]string{
+			"a":          {"href", "name", "rel", "target"},
+			"abbr":       nil,
+			"aside":      nil, // Callouts
+			"b":          nil,
+			"blockquote": {"cite"},
+			"br":         nil,
+			"caption":    nil,
+			"cite":       nil,
+			"code":       nil,
+			"col":        {"span"},
+			"colgroup":   {"span"},
+			"dd":         nil,
+			"del":        {"cite", "datetime"},
+			"details":    {"open"},
+			"div":        nil,
+			"dl":         nil,
+			"dt":         nil,
+			"em":         nil,
+			"figcaption": nil,
+			"figure":     nil,
+			"h1":         nil,
+			"h2":         nil,
+			"h3":         nil,
+			"h4":         nil,
+			"h5":         nil,
+			"h6":         nil,
+			"hr":         nil,
+			"i":          nil,
+			"img":        {"src", "srcset", "sizes", "alt", "width", "height", "loading", "decoding"},
+			"input":      {"type", "checked", "disabled"}, // GFM task lists
+			"ins":        {"cite", "datetime"},
+			"kbd":        nil,
+			"li":         {"value"},
+			"mark":       nil,
+			"ol":         {"start", "reversed"},
+			"p":          nil,
+			"picture":    nil,
+			"pre":        {"tabindex"}, // chroma makes code blocks focusable
+			"q":          {"cite"},
+			"s":          nil,
+			"samp":       nil,
+			"section":    nil,
+			"small":      nil,
+			"source":     {"srcset", "sizes", "type", "media"},
+			"span":       nil,
+			"strong":     nil,
+			"sub":        nil,
+			"summary":    nil,
+			"sup":        nil,
+			"table":      nil,
+			"tbody":      nil,
+			"td":         {"align", "colspan", "rowspan"},
+			"tfoot":      nil,
+			"th":         {"align", "colspan", "rowspan", "scope"},
+			"thead":      nil,
+			"time":       {"datetime"},
+			"tr":         nil,
+			"u":          nil,
+			"ul":         nil,
+			"var":        nil,
+
+			// Inline SVG diagrams
+			"svg":      svgAttributes,
+			"g":        svgAttributes,
+			"defs":     nil,
+			"marker":   svgAttributes,
+			"path":     svgAttributes,
+			"polygon":  svgAttributes,
+			"polyline": svgAttributes,
+			"line":     svgAttributes,
+			"rect":     svgAttributes,
+			"circle":   svgAttributes,
+			"ellipse":  svgAttributes,
+			"text":     svgAttributes,
+			"tspan":    svgAttributes,
+		},
+		// class keeps chroma token classes; id and role keep heading anchors
+		// and footnote references working
+		GlobalAttributes: []string{"class", "id", "title", "lang", "dir", "role", "aria-hidden", "aria-label"},
+		URLSchemes:       []string{"http", "https", "mailto", "tel"},
+		DropContent: []string{
+			"script", "style", "iframe", "frame", "frameset", "object", "embed",
+			"applet", "template", "noscript", "noembed", "noframes", "textarea",
+			"title", "xmp", "plaintext", "math",
+		},
+	}
+}
+
+// urlAttributes hold a single URL; srcset holds a list and is handled apart
+var urlAttributes = map[string]bool{"href": true, "src": true, "cite": true}
+
+// compile builds the lookup sets Sanitize uses from the policy fields
+func (p *Sanitizer) compile() {
+	p.allowed = make(map[string]map[string]bool, len(p.Elements))
+	for el, attrs := range p.Elements {
+		set := make(map[string]bool, len(attrs)+len(p.GlobalAttributes))
+		for _, a := range p.GlobalAttributes {
+			set[a] = true
+		}
+		for _, a := range attrs {
+			set[a] = true
+		}
+		p.allowed[el] = set
+	}
+	p.drop = make(map[string]bool, len(p.DropContent))
+	for _, el := range p.DropContent {
+		p.drop[el] = true
+	}
+}
+
+// Sanitize returns s with disallowed elements, attributes and URLs removed
+func (p *Sanitizer) Sanitize(s string) string {
+	p.once.Do(p.compile)
+	allowed, drop := p.allowed, p.drop
+
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	skipping, depth := "", 0 // Element whose content is being dropped
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				// The tokenizer only fails on read errors; keep what we have
+				break
+			}
+			return b.String()
+		}
+
+		tok := z.Token()
+		if skipping != "" {
+			switch {
+			case tt == html.StartTagToken && tok.Data == skipping:
+				depth++
+			case tt == html.EndTagToken && tok.Data == skipping:
+				if depth--; depth == 0 {
+					skipping = ""
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			b.WriteString(escapeHTML(tok.Data))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if drop[tok.Data] {
+				if tt == html.StartTagToken {
+					skipping, depth = tok.Data, 1
+				}
+				continue
+			}
+			attrs, ok := allowed[tok.Data]
+			if !ok {
+				continue
+			}
+			b.WriteByte('<')
+			b.WriteString(tok.Data)
+			for _, a := range tok.Attr {
+				if a.Namespace != "" || !attrs[a.Key] || !p.allowedValue(a.Key, a.Val) {
+					continue
+				}
+				b.WriteByte(' ')
+				b.WriteString(a.Key)
+				b.WriteString(`="`)
+				b.WriteString(escapeHTML(a.Val))
+				b.WriteByte('"')
+			}
+			if tt == html.SelfClosingTagToken {
+				b.WriteString(" /")
+			}
+			b.WriteByte('>')
+		case html.EndTagToken:
+			if _, ok := allowed[tok.Data]; ok {
+				b.WriteString("</" + tok.Data + ">")
+			}
+		}
+		// Comments and doctypes are dropped
+	}
+	return b.String()
+}
+
+// allowedValue checks the value of an otherwise allowed attribute
+func (p *Sanitizer) allowedValue(key, val string) bool {
+	switch {
+	case urlAttributes[key]:
+		return p.allowedURL(val)
+	case key == "srcset":
+		for _, candidate := range strings.Split(val, ",") {
+			fields := strings.Fields(candidate)
+			if len(fields) > 0 && !p.allowedURL(fields[0]) {
+				return false
+			}
+		}
+	case key == "target":
+		return val == "_blank" || val == "_self"
+	}
+	return true
+}
+
+// allowedURL reports whether u is relative or uses an allowed scheme.
+// Browsers ignore whitespace and control characters inside a scheme
+// ("java\tscript:"), so those are removed before it is parsed.
+func (p *Sanitizer) allowedURL(u string) bool {
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, u)
+	parsed, err := url.Parse(cleaned)
+	if err != nil {
+		return false
+	}
+	if parsed.Scheme == "" {
+		return true
+	}
+	for _, scheme := range p.URLSchemes {
+		if strings.EqualFold(parsed.Scheme, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlEscaper escapes text and attribute values the way goldmark does
+var htmlEscaper = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&quot;")
+
+// escapeHTML escapes s for text content or a double-quoted attribute
+func escapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
@@ -0,0 +1,115 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	s := DefaultSanitizer()
+
+	testCases := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "script removed with its content",
+			html:     `<p>Hi</p><script>alert(1)</script><p>there</p>`,
+			expected: `<p>Hi</p><p>there</p>`,
+		},
+		{
+			name:     "event handler attribute removed",
+			html:     `<img src="/a.png" onerror="alert(1)" alt="a" />`,
+			expected: `<img src="/a.png" alt="a" />`,
+		},
+		{
+			name:     "javascript URL removed",
+			html:     `<a href="javascript:alert(1)">x</a>`,
+			expected: `<a>x</a>`,
+		},
+		{
+			name:     "obfuscated scheme removed",
+			html:     `<a href="JaVa&#09;Script:alert(1)">x</a>`,
+			expected: `<a>x</a>`,
+		},
+		{
+			name:     "data URL in srcset removed",
+			html:     `<img src="/a.png" srcset="/a-480.webp 480w, data:image/png;base64,AAAA 960w" />`,
+			expected: `<img src="/a.png" />`,
+		},
+		{
+			name:     "unknown element unwrapped",
+			html:     `<p><marquee>moving</marquee> <form action="/x"><button>go</button></form></p>`,
+			expected: `<p>moving go</p>`,
+		},
+		{
+			name:     "comments and style attributes removed",
+			html:     `<!-- hidden --><p style="position:fixed">text</p>`,
+			expected: `<p>text</p>`,
+		},
+		{
+			name:     "chroma classes kept",
+			html:     `<pre tabindex="0" class="chroma"><code><span class="kd">func</span></code></pre>`,
+			expected: `<pre tabindex="0" class="chroma"><code><span class="kd">func</span></code></pre>`,
+		},
+		{
+			name:     "footnote anchors kept",
+			html:     `<sup id="fnref:1"><a href="#fn:1" class="footnote-ref" role="doc-noteref">1</a></sup>`,
+			expected: `<sup id="fnref:1"><a href="#fn:1" class="footnote-ref" role="doc-noteref">1</a></sup>`,
+		},
+		{
+			name:     "inline SVG kept without script or links",
+			html:     `<svg viewBox="0 0 8 8" onload="alert(1)"><g class="node"><title>a</title><a xlink:href="javascript:alert(1)"><ellipse cx="4" cy="4" rx="3" ry="2" fill="none" stroke="black"/></a><text x="4" y="5" text-anchor="middle">a</text></g><script>alert(1)</script></svg>`,
+			expected: `<svg viewbox="0 0 8 8"><g class="node"><a><ellipse cx="4" cy="4" rx="3" ry="2" fill="none" stroke="black" /></a><text x="4" y="5" text-anchor="middle">a</text></g></svg>`,
+		},
+		{
+			name:     "text re-escaped",
+			html:     `<p>a &lt;script&gt; &amp; &quot;b&quot;</p>`,
+			expected: `<p>a &lt;script&gt; &amp; &quot;b&quot;</p>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := s.Sanitize(tc.html); got != tc.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSanitizeCustomPolicy(t *testing.T) {
+	s := DefaultSanitizer()
+	s.Elements["iframe"] = []string{"src"}
+	s.DropContent = nil
+
+	got := s.Sanitize(`<iframe src="https://www.youtube.com/embed/x" onload="x()"></iframe>`)
+	if got != `<iframe src="https://www.youtube.com/embed/x"></iframe>` {
+		t.Errorf("Expected iframe allowed by the custom policy, got %s", got)
+	}
+}
+
+func TestParseSanitizesUnlessTrusted(t *testing.T) {
+	loader := NewLoader("./test", "")
+
+	body := "Hello <img src=x onerror=\"alert(1)\">\n\n<script>alert(2)</script>\n"
+	post, err := loader.ParseContent("---\ntitle: \"Guest\"\ndate: \"2025-09-15\"\n---\n\n"+body, "guest.md")
+	if err != nil {
+		t.Fatalf("ParseContent failed: %v", err)
+	}
+	if strings.Contains(post.HTML, "onerror") || strings.Contains(post.HTML, "script") {
+		t.Errorf("Expected unsafe HTML removed, got %s", post.HTML)
+	}
+	if !strings.Contains(post.HTML, `<img src="x">`) {
+		t.Errorf("Expected safe part of the image kept, got %s", post.HTML)
+	}
+
+	post, err = loader.ParseContent("---\ntitle: \"Embed\"\ndate: \"2025-09-15\"\ntrusted_html: true\n---\n\n"+body, "embed.md")
+	if err != nil {
+		t.Fatalf("ParseContent failed: %v", err)
+	}
+	if !strings.Contains(post.HTML, "<script>alert(2)</script>") {
+		t.Errorf("Expected trusted_html to keep raw HTML, got %s", post.HTML)
+	}
+}
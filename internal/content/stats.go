@@ -0,0 +1,123 @@
+package content
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// wordsPerMinute is the reading speed used for ReadingTime estimates
+const wordsPerMinute = 200
+
+// excerptMaxRunes caps the auto-excerpt used when a post has no summary
+const excerptMaxRunes = 280
+
+// statsKey carries the docStats collected while the document is transformed
+var statsKey = parser.NewContextKey()
+
+// docStats holds the prose measurements of a document
+type docStats struct {
+	words   int
+	excerpt string
+	code    bool // Has a fenced code block, which the highlighter renders
+}
+
+// statsTransformer counts the document's words and extracts its first
+// paragraph as plain text. Code blocks, raw HTML and image alt text are not
+// prose and are skipped. It runs after diagram fences are replaced, so the
+// fences it sees are highlighted code.
+type statsTransformer struct{}
+
+// Transform implements parser.ASTTransformer
+func (t *statsTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var stats docStats
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch v := n.(type) {
+		case *ast.FencedCodeBlock:
+			stats.code = true
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeBlock, *ast.HTMLBlock:
+			return ast.WalkSkipChildren, nil
+		case *ast.Heading, *ast.Paragraph, *ast.TextBlock:
+			prose := proseText(n, source)
+			stats.words += countWords(prose)
+			if stats.excerpt == "" && n.Kind() == ast.KindParagraph {
+				stats.excerpt = truncateRunes(prose, excerptMaxRunes)
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			// Text outside a paragraph, e.g. in a table cell
+			stats.words += countWords(string(v.Segment.Value(source)))
+		}
+		return ast.WalkContinue, nil
+	})
+
+	pc.Set(statsKey, stats)
+}
+
+// proseText is the readable text beneath n with whitespace collapsed
+func proseText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch v := c.(type) {
+		case *ast.Image, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			b.Write(v.Segment.Value(source))
+			if v.SoftLineBreak() || v.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(v.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// countWords counts whitespace-separated runs that contain a letter or digit
+func countWords(s string) int {
+	count := 0
+	for _, field := range strings.Fields(s) {
+		if strings.IndexFunc(field, func(r rune) bool {
+			return unicode.IsLetter(r) || unicode.IsDigit(r)
+		}) >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// truncateRunes shortens s to at most max runes, cutting at a word boundary
+// and marking the cut with an ellipsis
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	cut := string(runes[:max])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:.-") + "…"
+}
+
+// ReadingTime estimates minutes to read words at wordsPerMinute, rounding up.
+// Any non-empty post takes at least a minute.
+func ReadingTime(words int) int {
+	if words <= 0 {
+		return 0
+	}
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
@@ -0,0 +1,73 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderDocumentStats(t *testing.T) {
+	renderer := NewRenderer()
+
+	markdown := "# Title\n\n![diagram](diagram.png)\n\nThe **first** paragraph has a [link](https://example.com)\nand `code`.\n\n```go\nfunc main() { fmt.Println(\"not prose\") }\n```\n\nSecond paragraph.\n"
+	doc, err := renderer.RenderDocument(markdown, RenderOptions{})
+	if err != nil {
+		t.Fatalf("RenderDocument failed: %v", err)
+	}
+
+	// Image-only paragraphs are skipped; markup is stripped
+	if doc.Excerpt != "The first paragraph has a link and code." {
+		t.Errorf("Unexpected excerpt: %q", doc.Excerpt)
+	}
+
+	// Title (1) + first paragraph (8) + second paragraph (2); code is not counted
+	if doc.WordCount != 11 {
+		t.Errorf("Expected 11 words, got %d", doc.WordCount)
+	}
+	if !doc.Code {
+		t.Error("Expected the go fence to be recorded as code")
+	}
+
+	// A diagram fence is not code
+	doc, err = renderer.RenderDocument("```mermaid\ngraph TD\n```\n\nProse with `inline` code.\n", RenderOptions{})
+	if err != nil {
+		t.Fatalf("RenderDocument failed: %v", err)
+	}
+	if doc.Code {
+		t.Error("Expected no code blocks recorded for a diagram")
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	if got := truncateRunes("short", 10); got != "short" {
+		t.Errorf("Expected untouched string, got %q", got)
+	}
+
+	got := truncateRunes("one two three, four five", 16)
+	if got != "one two three…" {
+		t.Errorf("Expected cut at a word boundary, got %q", got)
+	}
+
+	long := strings.Repeat("ä", 300)
+	if got := truncateRunes(long, excerptMaxRunes); len([]rune(got)) != excerptMaxRunes+1 {
+		t.Errorf("Expected %d runes plus ellipsis, got %d", excerptMaxRunes, len([]rune(got)))
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		words    int
+		expected int
+	}{
+		{0, 0},
+		{1, 1},
+		{200, 1},
+		{201, 2},
+		{1000, 5},
+	}
+
+	for _, test := range tests {
+		if got := ReadingTime(test.words); got != test.expected {
+			t.Errorf("ReadingTime(%d) = %d, expected %d", test.words, got, test.expected)
+		}
+	}
+}
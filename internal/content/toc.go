@@ -0,0 +1,127 @@
+package content
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"notebook.oceanheart.ai/internal/store"
+)
+
+// Heading levels included in the table of contents; h1 is the post title
+const (
+	tocMinLevel = 2
+	tocMaxLevel = 4
+)
+
+// headingsKey collects the document's headings while it is transformed
+var headingsKey = parser.NewContextKey()
+
+// tocEntry is a flat heading record, nested into a tree by buildTOC
+type tocEntry struct {
+	level int
+	id    string
+	text  string
+}
+
+// headingTransformer records every heading for the table of contents and
+// appends a hover permalink anchor (styled by .anchor in app.css)
+type headingTransformer struct{}
+
+// Transform implements parser.ASTTransformer
+func (t *headingTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var entries []tocEntry
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		heading, ok := n.(*ast.Heading)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		idAttr, ok := heading.AttributeString("id")
+		if !ok {
+			return ast.WalkSkipChildren, nil
+		}
+		id, ok := idAttr.([]byte)
+		if !ok || len(id) == 0 {
+			return ast.WalkSkipChildren, nil
+		}
+
+		entries = append(entries, tocEntry{
+			level: heading.Level,
+			id:    string(id),
+			text:  strings.TrimSpace(nodeText(heading, source)),
+		})
+
+		anchor := ast.NewLink()
+		anchor.Destination = append([]byte("#"), id...)
+		anchor.Title = []byte("Permalink to this section")
+		anchor.SetAttributeString("class", []byte("anchor"))
+		anchor.AppendChild(anchor, ast.NewString([]byte("#")))
+		heading.AppendChild(heading, ast.NewString([]byte(" ")))
+		heading.AppendChild(heading, anchor)
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	pc.Set(headingsKey, entries)
+}
+
+// nodeText concatenates the visible text beneath n
+func nodeText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch v := c.(type) {
+		case *ast.Text:
+			b.Write(v.Segment.Value(source))
+			if v.SoftLineBreak() || v.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(v.Value)
+		case *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
+
+// buildTOC nests the flat heading list into a tree, keeping levels
+// tocMinLevel..tocMaxLevel. A heading that skips a level attaches to the
+// nearest shallower heading.
+func buildTOC(entries []tocEntry) []store.Heading {
+	var root []store.Heading
+	// stack holds the path of open headings as pointers into the tree
+	var stack []*store.Heading
+
+	for _, e := range entries {
+		if e.level < tocMinLevel || e.level > tocMaxLevel {
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].Level >= e.level {
+			stack = stack[:len(stack)-1]
+		}
+
+		h := store.Heading{Level: e.level, ID: e.id, Text: e.text}
+		if len(stack) == 0 {
+			root = append(root, h)
+			stack = append(stack, &root[len(root)-1])
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, h)
+			stack = append(stack, &parent.Children[len(parent.Children)-1])
+		}
+	}
+
+	return root
+}
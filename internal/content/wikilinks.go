@@ -0,0 +1,144 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// knownSlugsKey carries the set of post slugs wiki-links resolve against
+var knownSlugsKey = parser.NewContextKey()
+
+// kindWikiLink is the AST node kind of a [[slug]] link
+var kindWikiLink = ast.NewNodeKind("WikiLink")
+
+// wikiLink is an inline link to another post written as [[slug]] or
+// [[slug|label]]. Its children are the label text.
+type wikiLink struct {
+	ast.BaseInline
+	Target string // Slug of the linked post
+	Broken bool   // No post with Target was loaded
+}
+
+// Kind implements ast.Node
+func (n *wikiLink) Kind() ast.NodeKind {
+	return kindWikiLink
+}
+
+// Dump implements ast.Node
+func (n *wikiLink) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Target": n.Target,
+		"Broken": fmt.Sprint(n.Broken),
+	}, nil)
+}
+
+// wikiLinks is a goldmark extension linking [[slug]] references to /p/{slug}
+type wikiLinks struct{}
+
+// Extend implements goldmark.Extender
+func (e *wikiLinks) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(
+		// Ahead of the link parser (200), which would read [[a]] as text
+		util.Prioritized(&wikiLinkParser{}, 199),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&wikiLinkRenderer{}, 500),
+	))
+}
+
+// wikiLinkParser parses [[slug]] and [[slug|label]] on a single line
+type wikiLinkParser struct{}
+
+// Trigger implements parser.InlineParser
+func (p *wikiLinkParser) Trigger() []byte {
+	return []byte{'['}
+}
+
+// Parse implements parser.InlineParser
+func (p *wikiLinkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, seg := block.PeekLine()
+	if !bytes.HasPrefix(line, []byte("[[")) {
+		return nil
+	}
+	end := bytes.Index(line[2:], []byte("]]"))
+	if end < 0 {
+		return nil
+	}
+	inner := line[2 : 2+end]
+	if bytes.ContainsAny(inner, "[]") {
+		return nil
+	}
+
+	targetEnd := len(inner)
+	if i := bytes.IndexByte(inner, '|'); i >= 0 {
+		targetEnd = i
+	}
+	target := strings.TrimSpace(string(inner[:targetEnd]))
+	if target == "" {
+		return nil
+	}
+
+	// The label is the text after the pipe, or the target itself
+	start := seg.Start + 2
+	label := trimSegment(text.NewSegment(start, start+targetEnd), block.Source())
+	if targetEnd < len(inner) {
+		if l := trimSegment(text.NewSegment(start+targetEnd+1, start+len(inner)), block.Source()); !l.IsEmpty() {
+			label = l
+		}
+	}
+
+	known, _ := pc.Get(knownSlugsKey).(map[string]bool)
+	node := &wikiLink{Target: target, Broken: known != nil && !known[target]}
+	node.AppendChild(node, ast.NewTextSegment(label))
+
+	block.Advance(2 + end + 2)
+	return node
+}
+
+// trimSegment returns seg without surrounding spaces
+func trimSegment(seg text.Segment, source []byte) text.Segment {
+	seg = seg.TrimLeftSpace(source)
+	return seg.TrimRightSpace(source)
+}
+
+// wikiLinkRenderer renders wiki-links as ordinary anchors. Broken links keep
+// their href so the post can be created later, and are styled apart.
+type wikiLinkRenderer struct{}
+
+// RegisterFuncs implements renderer.NodeRenderer
+func (r *wikiLinkRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindWikiLink, r.render)
+}
+
+func (r *wikiLinkRenderer) render(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		_, _ = w.WriteString("</a>")
+		return ast.WalkContinue, nil
+	}
+
+	link := n.(*wikiLink)
+	_, _ = w.WriteString(`<a href="`)
+	_, _ = w.Write(util.EscapeHTML([]byte(postPath(link.Target))))
+	if link.Broken {
+		_, _ = w.WriteString(`" class="wikilink wikilink-broken" title="No post named `)
+		_, _ = w.Write(util.EscapeHTML([]byte(link.Target)))
+		_, _ = w.WriteString(`">`)
+	} else {
+		_, _ = w.WriteString(`" class="wikilink">`)
+	}
+	return ast.WalkContinue, nil
+}
+
+// postPath returns the URL path of the post with slug
+func postPath(slug string) string {
+	return "/p/" + url.PathEscape(slug)
+}
@@ -0,0 +1,134 @@
+package content
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderWikiLinks(t *testing.T) {
+	renderer := NewRenderer()
+	slugs := map[string]bool{"alpha": true, "beta": true}
+
+	testCases := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{
+			name:     "slug as label",
+			markdown: "See [[alpha]].",
+			expected: `<p>See <a href="/p/alpha" class="wikilink">alpha</a>.</p>`,
+		},
+		{
+			name:     "custom label",
+			markdown: "See [[ beta | the second post ]].",
+			expected: `<p>See <a href="/p/beta" class="wikilink">the second post</a>.</p>`,
+		},
+		{
+			name:     "empty label falls back to slug",
+			markdown: "[[alpha|]]",
+			expected: `<p><a href="/p/alpha" class="wikilink">alpha</a></p>`,
+		},
+		{
+			name:     "unknown target is broken",
+			markdown: "[[gone|Gone]]",
+			expected: `<p><a href="/p/gone" class="wikilink wikilink-broken" title="No post named gone">Gone</a></p>`,
+		},
+		{
+			name:     "ordinary links unchanged",
+			markdown: "[alpha](/p/alpha) and [[]]",
+			expected: `<p><a href="/p/alpha">alpha</a> and [[]]</p>`,
+		},
+		{
+			name:     "code spans unchanged",
+			markdown: "`[[alpha]]`",
+			expected: `<p><code>[[alpha]]</code></p>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := renderer.RenderDocument(tc.markdown, RenderOptions{Slugs: slugs})
+			if err != nil {
+				t.Fatalf("RenderDocument failed: %v", err)
+			}
+			if got := strings.TrimSpace(doc.HTML); got != tc.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestRenderWikiLinksArePostLinks(t *testing.T) {
+	renderer := NewRenderer()
+
+	doc, err := renderer.RenderDocument("[[beta]], [[alpha|A]] and [[beta]] again.", RenderOptions{})
+	if err != nil {
+		t.Fatalf("RenderDocument failed: %v", err)
+	}
+	if want, got := []string{"beta", "alpha"}, PostLinks(doc.HTML, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected links %v, got %v", want, got)
+	}
+
+	// Without a slug set nothing is known to be missing
+	if strings.Contains(doc.HTML, "wikilink-broken") {
+		t.Errorf("Expected no broken links without a slug set, got %s", doc.HTML)
+	}
+}
+
+func TestLoadAllReportsBrokenWikiLinks(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"2025-09-01-alpha.md": "---\ntitle: \"Alpha\"\ndate: \"2025-09-01\"\n---\n\nSee [[beta]] and [[missing]].\n",
+		"beta/index.md":       "---\ntitle: \"Beta\"\ndate: \"2025-09-02\"\n---\n\nBack to [[alpha]].\n",
+	}
+	for name, body := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	posts, err := NewLoader(tempDir, "").LoadAll()
+	if len(posts) != 2 {
+		t.Fatalf("Expected both posts to load, got %d", len(posts))
+	}
+
+	loadErrs := AsLoadErrors(err)
+	if len(loadErrs) != 1 {
+		t.Fatalf("Expected 1 warning, got %v", err)
+	}
+	le := loadErrs[0]
+	if !le.Warning || filepath.Base(le.Path) != "2025-09-01-alpha.md" || !strings.Contains(le.Reason, `"missing"`) {
+		t.Errorf("Unexpected warning %+v", le)
+	}
+	if len(loadErrs.Failed()) != 0 {
+		t.Errorf("Expected no failed files, got %v", loadErrs.Failed())
+	}
+
+	for _, post := range posts {
+		switch post.Slug {
+		case "alpha":
+			// Links resolve to slugs loaded later in the walk, including bundles
+			if !strings.Contains(post.HTML, `<a href="/p/beta" class="wikilink">`) {
+				t.Errorf("Expected a resolved link to beta, got %s", post.HTML)
+			}
+			if !strings.Contains(post.HTML, "wikilink-broken") {
+				t.Errorf("Expected the missing link to be marked broken, got %s", post.HTML)
+			}
+			if want := []string{"beta", "missing"}; !reflect.DeepEqual(post.Links, want) {
+				t.Errorf("Expected links %v, got %v", want, post.Links)
+			}
+		case "beta":
+			if want := []string{"alpha"}; !reflect.DeepEqual(post.Links, want) {
+				t.Errorf("Expected links %v, got %v", want, post.Links)
+			}
+		}
+	}
+}
@@ -50,8 +50,20 @@ type AtomContent struct {
 	Body string `xml:",chardata"`
 }
 
-// GenerateAtom creates an Atom 1.0 feed from posts
+// FeedInfo describes the page a feed syndicates
+type FeedInfo struct {
+	Title string // Feed title
+	Path  string // Site-relative path of the HTML page, with trailing slash (e.g. "/projects/")
+}
+
+// GenerateAtom creates the site-wide Atom 1.0 feed from posts
 func GenerateAtom(posts []*store.Post, cfg *config.Config) ([]byte, error) {
+	return GenerateAtomFeed(posts, cfg, FeedInfo{Title: cfg.SiteTitle, Path: "/"})
+}
+
+// GenerateAtomFeed creates an Atom 1.0 feed for the page described by info.
+// The feed itself is expected to be served at info.Path + "feed.xml".
+func GenerateAtomFeed(posts []*store.Post, cfg *config.Config, info FeedInfo) ([]byte, error) {
 	if len(posts) == 0 {
 		return nil, fmt.Errorf("no posts available for feed")
 	}
@@ -65,12 +77,12 @@ func GenerateAtom(posts []*store.Post, cfg *config.Config) ([]byte, error) {
 	// Create feed structure
 	feed := AtomFeed{
 		Xmlns: "http://www.w3.org/2005/Atom",
-		Title: cfg.SiteTitle,
+		Title: info.Title,
 		Link: []AtomLink{
-			{Href: cfg.SiteBaseURL, Rel: "alternate", Type: "text/html"},
-			{Href: cfg.SiteBaseURL + "/feed.xml", Rel: "self", Type: "application/atom+xml"},
+			{Href: cfg.SiteBaseURL + info.Path, Rel: "alternate", Type: "text/html"},
+			{Href: cfg.SiteBaseURL + info.Path + "feed.xml", Rel: "self", Type: "application/atom+xml"},
 		},
-		ID:      cfg.SiteBaseURL + "/",
+		ID:      cfg.SiteBaseURL + info.Path,
 		Updated: formatAtomDate(feedPosts[0].UpdatedAt),
 		Author: AtomAuthor{
 			Name: "Oceanheart",
@@ -92,7 +104,7 @@ func GenerateAtom(posts []*store.Post, cfg *config.Config) ([]byte, error) {
 			},
 			ID:      cfg.SiteBaseURL + "/p/" + post.Slug,
 			Updated: formatAtomDate(post.UpdatedAt),
-			Summary: post.Summary,
+			Summary: post.Description(),
 			Content: AtomContent{
 				Type: "html",
 				Body: post.HTML,
@@ -3,10 +3,12 @@ package feed
 import (
 	"encoding/xml"
 	"fmt"
+	"sort"
 	"time"
 
 	"notebook.oceanheart.ai/internal/config"
 	"notebook.oceanheart.ai/internal/store"
+	"notebook.oceanheart.ai/internal/taxonomy"
 )
 
 // SitemapIndex represents a sitemap.xml structure
@@ -38,6 +40,27 @@ func GenerateSitemap(posts []*store.Post, cfg *config.Config) ([]byte, error) {
 		Priority:   "1.0",
 	})
 
+	// Add section index pages for sections that have published posts
+	seenSections := make(map[string]bool)
+	for _, post := range posts {
+		if post.Section == "" || seenSections[post.Section] || (post.Draft && cfg.Environment != "dev") {
+			continue
+		}
+		seenSections[post.Section] = true
+		sitemap.URLs = append(sitemap.URLs, SitemapURL{
+			Loc:        cfg.SiteBaseURL + "/" + post.Section + "/",
+			LastMod:    formatSitemapDate(time.Now()),
+			ChangeFreq: "weekly",
+			Priority:   "0.6",
+		})
+	}
+
+	// Add the archive index and a page per year and month with visible posts
+	sitemap.URLs = append(sitemap.URLs, archiveURLs(posts, cfg)...)
+
+	// Add the tag index and a page per tag on a visible post
+	sitemap.URLs = append(sitemap.URLs, tagURLs(posts, cfg)...)
+
 	// Add posts
 	for _, post := range posts {
 		// Skip drafts in production
@@ -78,6 +101,91 @@ func GenerateSitemap(posts []*store.Post, cfg *config.Config) ([]byte, error) {
 	return xmlWithDeclaration, nil
 }
 
+// archiveURLs lists /archive plus the /{yyyy}/ and /{yyyy}/{mm}/ pages that
+// have at least one visible post, newest first. Periods use the site's zone.
+func archiveURLs(posts []*store.Post, cfg *config.Config) []SitemapURL {
+	loc := cfg.Location()
+	seen := make(map[string]bool)
+	var periods []string
+
+	for _, post := range posts {
+		if post.Draft && cfg.Environment != "dev" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, post.PublishedAt)
+		if err != nil {
+			continue
+		}
+		t = t.In(loc)
+		for _, p := range []string{t.Format("/2006/"), t.Format("/2006/01/")} {
+			if !seen[p] {
+				seen[p] = true
+				periods = append(periods, p)
+			}
+		}
+	}
+	if len(periods) == 0 {
+		return nil
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(periods)))
+
+	urls := []SitemapURL{{
+		Loc:        cfg.SiteBaseURL + "/archive",
+		LastMod:    formatSitemapDate(time.Now()),
+		ChangeFreq: "weekly",
+		Priority:   "0.4",
+	}}
+	for _, p := range periods {
+		urls = append(urls, SitemapURL{
+			Loc:        cfg.SiteBaseURL + p,
+			LastMod:    formatSitemapDate(time.Now()),
+			ChangeFreq: "monthly",
+			Priority:   "0.3",
+		})
+	}
+	return urls
+}
+
+// tagURLs lists /tags plus a /tag/{name} page for every tag used by a
+// visible post and every namespace parent above one, in name order
+func tagURLs(posts []*store.Post, cfg *config.Config) []SitemapURL {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, post := range posts {
+		if post.Draft && cfg.Environment != "dev" {
+			continue
+		}
+		for _, tag := range post.Tags {
+			for _, t := range taxonomy.Ancestors(tag) {
+				if !seen[t] {
+					seen[t] = true
+					tags = append(tags, t)
+				}
+			}
+		}
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	sort.Strings(tags)
+
+	urls := []SitemapURL{{
+		Loc:        cfg.SiteBaseURL + "/tags",
+		LastMod:    formatSitemapDate(time.Now()),
+		ChangeFreq: "weekly",
+		Priority:   "0.4",
+	}}
+	for _, tag := range tags {
+		urls = append(urls, SitemapURL{
+			Loc:        cfg.SiteBaseURL + taxonomy.TagURL(tag),
+			LastMod:    formatSitemapDate(time.Now()),
+			ChangeFreq: "weekly",
+			Priority:   "0.4",
+		})
+	}
+	return urls
+}
+
 // formatSitemapDate formats time to W3C datetime format for sitemaps
 func formatSitemapDate(t time.Time) string {
 	return t.Format("2006-01-02T15:04:05-07:00")
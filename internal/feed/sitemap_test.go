@@ -27,6 +27,7 @@ func TestGenerateSitemap(t *testing.T) {
 			PublishedAt: "2025-09-12T10:00:00Z",
 			UpdatedAt:   "2025-09-12T10:00:00Z",
 			Draft:       false,
+			Tags:        []string{"go", "cognitive-skill:reflection"},
 		},
 		{
 			Slug:        "test-post-2",
@@ -47,6 +48,7 @@ func TestGenerateSitemap(t *testing.T) {
 			PublishedAt: "2025-09-10T10:00:00Z",
 			UpdatedAt:   "2025-09-10T10:00:00Z",
 			Draft:       true,
+			Tags:        []string{"unreleased"},
 		},
 	}
 
@@ -69,10 +71,12 @@ func TestGenerateSitemap(t *testing.T) {
 
 	// Should have:
 	// - Home page (1)
+	// - Archive index, 2025 and 2025-09 pages (3)
+	// - Tag index, the published posts' tags and their namespace parent (4, excluding the draft's)
 	// - Published posts (2, excluding draft)
 	// - Feed URL (1)
-	// Total: 4 URLs
-	expectedURLs := 4
+	// Total: 11 URLs
+	expectedURLs := 11
 	if len(sitemap.URLs) != expectedURLs {
 		t.Errorf("Expected %d URLs, got %d", expectedURLs, len(sitemap.URLs))
 	}
@@ -127,6 +131,24 @@ func TestGenerateSitemap(t *testing.T) {
 		t.Error("Expected feed URL in sitemap")
 	}
 
+	// Verify archive and tag URLs
+	for _, loc := range []string{
+		"https://example.com/archive", "https://example.com/2025/", "https://example.com/2025/09/",
+		"https://example.com/tags", "https://example.com/tag/go", "https://example.com/tag/cognitive-skill/reflection",
+		"https://example.com/tag/cognitive-skill",
+	} {
+		found := false
+		for _, url := range sitemap.URLs {
+			if url.Loc == loc {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected %s in sitemap", loc)
+		}
+	}
+
 	// Verify XML structure
 	xmlString := string(sitemapXML)
 	if !strings.Contains(xmlString, "<?xml") {
@@ -180,8 +202,8 @@ func TestGenerateSitemapWithDrafts(t *testing.T) {
 	}
 
 	// In dev mode, should include drafts
-	// Home (1) + Published post (1) + Draft post (1) + Feed (1) = 4 URLs
-	expectedURLs := 4
+	// Home (1) + Archive pages (3) + Published post (1) + Draft post (1) + Feed (1) = 7 URLs
+	expectedURLs := 7
 	if len(sitemap.URLs) != expectedURLs {
 		t.Errorf("Expected %d URLs in dev mode, got %d", expectedURLs, len(sitemap.URLs))
 	}
@@ -1,38 +1,73 @@
 package http
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"notebook.oceanheart.ai/internal/config"
 	"notebook.oceanheart.ai/internal/content"
 	"notebook.oceanheart.ai/internal/feed"
+	"notebook.oceanheart.ai/internal/imaging"
 	"notebook.oceanheart.ai/internal/store"
+	"notebook.oceanheart.ai/internal/taxonomy"
 	"notebook.oceanheart.ai/internal/view"
 )
 
 // Server holds the HTTP server dependencies
 type Server struct {
-	store *store.Store
-	cfg   *config.Config
-	view  *view.Manager
+	store  *store.Store
+	cfg    *config.Config
+	view   *view.Manager
+	images *imaging.Processor
+	loc    *time.Location // Site time zone for archive periods
+
+	mu         sync.RWMutex
+	loadErrors content.LoadErrors // failures from the most recent content load
+
+	chromaOnce sync.Once
+	chromaCSS  []byte // Highlighting stylesheet, built on first request
+	chromaETag string
 }
 
 // NewServer creates a new HTTP server
 func NewServer(store *store.Store, cfg *config.Config) *Server {
-	s := &Server{store: store, cfg: cfg}
+	s := &Server{store: store, cfg: cfg, loc: cfg.Location()}
 	s.view = view.NewManager("internal/view/templates", cfg.IsDev())
+	s.view.SetLocation(s.loc)
+	s.images = imaging.NewProcessor(cfg.ImageCacheDir, imaging.DefaultWidths)
 	return s
 }
 
 // loadTemplates compiles all HTML templates
 func (s *Server) loadTemplates() {}
 
+// SetLoadErrors records the per-file failures from the most recent content load
+func (s *Server) SetLoadErrors(errs content.LoadErrors) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadErrors = errs
+}
+
+// LoadErrors returns the per-file failures from the most recent content load
+func (s *Server) LoadErrors() content.LoadErrors {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.loadErrors
+}
+
 // getPopularTags retrieves popular tags for navigation
 func (s *Server) getPopularTags() []store.PopularTag {
 	tags, err := s.store.GetPopularTags(10)
@@ -53,11 +88,77 @@ func (s *Server) getCategorizedTags() []store.CategoryGroup {
 	return groups
 }
 
+// relatedPostCount is how many related posts are listed under a post
+const relatedPostCount = 3
+
+// getRelatedPosts retrieves the posts listed under a post
+func (s *Server) getRelatedPosts(post *store.Post) []*store.Post {
+	related, err := s.store.GetRelatedPosts(post.ID, relatedPostCount)
+	if err != nil {
+		log.Printf("Error loading related posts for %s: %v", post.Slug, err)
+		return nil
+	}
+	return related
+}
+
+// getBacklinks retrieves the published posts linking to a post
+func (s *Server) getBacklinks(post *store.Post) []*store.Post {
+	backlinks, err := s.store.GetBacklinks(post.Slug)
+	if err != nil {
+		log.Printf("Error loading backlinks for %s: %v", post.Slug, err)
+		return nil
+	}
+	return backlinks
+}
+
+// getAdjacentPosts retrieves the chronological neighbours of a post
+func (s *Server) getAdjacentPosts(post *store.Post) (prev, next *store.Post) {
+	prev, next, err := s.store.GetAdjacentPosts(post)
+	if err != nil {
+		log.Printf("Error loading adjacent posts for %s: %v", post.Slug, err)
+		return nil, nil
+	}
+	return prev, next
+}
+
+// getSeriesParts retrieves the parts of the post's series and the post's
+// 1-based position among them (0 if it is not listed, e.g. a draft)
+func (s *Server) getSeriesParts(post *store.Post) ([]*store.Post, int) {
+	if post.Series == "" {
+		return nil, 0
+	}
+	parts, err := s.store.GetSeries(post.Series)
+	if err != nil {
+		log.Printf("Error loading series %s: %v", post.Series, err)
+		return nil, 0
+	}
+	for i, part := range parts {
+		if part.ID == post.ID {
+			return parts, i + 1
+		}
+	}
+	return parts, 0
+}
+
+// getSections retrieves content sections for the home page
+func (s *Server) getSections() []store.Section {
+	sections, err := s.store.GetSections()
+	if err != nil {
+		log.Printf("Error loading sections: %v", err)
+		return []store.Section{}
+	}
+	return sections
+}
+
 // HomeHandler serves the home page with post listings
 func (s *Server) HomeHandler(w http.ResponseWriter, r *http.Request) {
-	// Only serve root path
+	// Anything below the root is a date archive, a section index or a 404
 	if r.URL.Path != "/" {
-		http.NotFound(w, r)
+		if year, month, ok := parseArchivePath(r.URL.Path); ok {
+			s.ArchivePeriodHandler(w, r, year, month)
+			return
+		}
+		s.SectionHandler(w, r)
 		return
 	}
 
@@ -77,6 +178,7 @@ func (s *Server) HomeHandler(w http.ResponseWriter, r *http.Request) {
 		"IsPost":       false,
 		"Posts":        posts,
 		"HasPosts":        len(posts) > 0,
+		"Sections":        s.getSections(),
 		"PopularTags":     s.getPopularTags(),
 		"CategorizedTags": s.getCategorizedTags(),
 		"ActiveTag":       "",
@@ -100,12 +202,16 @@ func (s *Server) HomeHandler(w http.ResponseWriter, r *http.Request) {
 
 // PostHandler serves individual post pages
 func (s *Server) PostHandler(w http.ResponseWriter, r *http.Request) {
-	// Extract slug from URL path /p/{slug}
+	// Extract slug from URL path /p/{slug}, or /p/{slug}/{asset} for bundle files
 	slug := strings.TrimPrefix(r.URL.Path, "/p/")
 	if slug == "" || slug == r.URL.Path {
 		http.NotFound(w, r)
 		return
 	}
+	var asset string
+	if i := strings.Index(slug, "/"); i != -1 {
+		slug, asset = slug[:i], slug[i+1:]
+	}
 
 	post, err := s.store.GetPostBySlug(slug)
 	if err != nil {
@@ -125,16 +231,33 @@ func (s *Server) PostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if asset != "" {
+		s.serveBundleAsset(w, r, post, asset)
+		return
+	}
+
+	prev, next := s.getAdjacentPosts(post)
+	seriesParts, seriesPart := s.getSeriesParts(post)
+
 	data := map[string]interface{}{
 		"Title":        post.Title,
 		"SiteTitle":    s.cfg.SiteTitle,
-		"Description":  post.Summary,
+		"Description":  post.Description(),
 		"CanonicalURL": s.cfg.SiteBaseURL + "/p/" + post.Slug,
 		"BaseURL":      s.cfg.SiteBaseURL,
 		"IsPost":       true,
 		"PublishedAt":  post.PublishedAt,
 		"UpdatedAt":    post.UpdatedAt,
+		"Math":         post.Math,
+		"Mermaid":      post.Mermaid,
+		"CopyButtons":  s.cfg.CodeCopyButtons && post.Code,
 		"Post":            post,
+		"RelatedPosts":    s.getRelatedPosts(post),
+		"Backlinks":       s.getBacklinks(post),
+		"PrevPost":        prev,
+		"NextPost":        next,
+		"SeriesParts":     seriesParts,
+		"SeriesPart":      seriesPart,
 		"PopularTags":     s.getPopularTags(),
 		"CategorizedTags": s.getCategorizedTags(),
 		"ActiveTag":       "",
@@ -156,31 +279,129 @@ func (s *Server) PostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// serveBundleAsset streams a file stored alongside a page bundle's index.md
+func (s *Server) serveBundleAsset(w http.ResponseWriter, r *http.Request, post *store.Post, asset string) {
+	if post.BundleDir == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	clean := filepath.Clean(filepath.FromSlash(asset))
+	// Prevent directory traversal and never expose the markdown source
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) || filepath.IsAbs(clean) || strings.EqualFold(filepath.Ext(clean), ".md") {
+		http.NotFound(w, r)
+		return
+	}
+
+	fp := filepath.Join(s.cfg.ContentDir, filepath.FromSlash(post.BundleDir), clean)
+	st, err := os.Stat(fp)
+	if err != nil || st.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	if s.cfg.IsDev() {
+		w.Header().Set("Cache-Control", "no-store, max-age=0")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=86400") // Cache for 24 hours
+	}
+	// ServeFile sets Content-Type from the extension and handles conditional requests
+	http.ServeFile(w, r, fp)
+}
+
+// ImageHandler serves resized WebP variants of page bundle images at
+// /img/{slug}/{width}/{file}.webp, generating and caching them on first request
+func (s *Server) ImageHandler(w http.ResponseWriter, r *http.Request) {
+	slug, width, file, ok := imaging.ParseVariantPath(r.URL.Path)
+	if !ok || !s.images.AllowsWidth(width) {
+		http.NotFound(w, r)
+		return
+	}
+
+	post, err := s.store.GetPostBySlug(slug)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		log.Printf("Error getting post %s: %v", slug, err)
+		return
+	}
+	if post == nil || post.BundleDir == "" || (post.Draft && !s.cfg.IsDev()) {
+		http.NotFound(w, r)
+		return
+	}
+
+	src := filepath.Join(s.cfg.ContentDir, filepath.FromSlash(post.BundleDir), filepath.FromSlash(file))
+	if st, err := os.Stat(src); err != nil || st.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	variant, err := s.images.Variant(src, width)
+	if err != nil {
+		http.Error(w, "Failed to process image", http.StatusInternalServerError)
+		log.Printf("Error generating %dpx variant of %s: %v", width, src, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/webp")
+	if s.cfg.IsDev() {
+		w.Header().Set("Cache-Control", "no-store, max-age=0")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=604800") // Cache for 7 days
+	}
+	http.ServeFile(w, r, variant)
+}
+
 // TagHandler serves tag filtering pages
 func (s *Server) TagHandler(w http.ResponseWriter, r *http.Request) {
-	// Extract tag from URL path /tag/{name}
-	tagName := strings.TrimPrefix(r.URL.Path, "/tag/")
-	if tagName == "" || tagName == r.URL.Path {
+	// Extract tag from URL path /tag/{ns}/{name}; each segment is one
+	// namespace level
+	if r.URL.Path == "/tag/" {
+		http.Redirect(w, r, "/tags", http.StatusMovedPermanently)
+		return
+	}
+	tagName, ok := taxonomy.ParseTagPath(r.URL.EscapedPath())
+	if !ok {
 		http.NotFound(w, r)
 		return
 	}
 
-	// Get posts with this tag
+	// Aliases from the taxonomy and legacy "ns:name" URLs redirect to the
+	// canonical tag URL
+	canonical := s.store.Taxonomy().Canonical(tagName)
+	if target := taxonomy.TagURL(canonical); canonical != tagName || target != r.URL.EscapedPath() {
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+
+	// Get posts with this tag or any tag beneath it
 	posts, err := s.store.GetPostsByTag(tagName)
 	if err != nil {
 		http.Error(w, "Database error", http.StatusInternalServerError)
 		log.Printf("Error fetching posts by tag %s: %v", tagName, err)
 		return
 	}
+	childTags, err := s.store.GetChildTags(tagName)
+	if err != nil {
+		log.Printf("Error fetching child tags of %s: %v", tagName, err)
+	}
+	displayName := tagName
+	if tag, err := s.store.GetTag(tagName); err != nil {
+		log.Printf("Error fetching tag %s: %v", tagName, err)
+	} else if tag != nil && tag.DisplayName != "" {
+		displayName = tag.DisplayName
+	}
 
 	data := map[string]interface{}{
-		"Title":           fmt.Sprintf("Tag: %s", tagName),
+		"Title":           fmt.Sprintf("Tag: %s", displayName),
 		"SiteTitle":       s.cfg.SiteTitle,
-		"Description":     fmt.Sprintf("Posts tagged with %s", tagName),
-		"CanonicalURL":    s.cfg.SiteBaseURL + "/tag/" + tagName,
+		"Description":     fmt.Sprintf("Posts tagged with %s", displayName),
+		"CanonicalURL":    s.cfg.SiteBaseURL + taxonomy.TagURL(tagName),
 		"BaseURL":         s.cfg.SiteBaseURL,
 		"IsPost":          false,
 		"Tag":             tagName,
+		"TagName":         displayName,
+		"Breadcrumbs":     tagBreadcrumbs(tagName, displayName),
+		"ChildTags":       childTags,
 		"Posts":           posts,
 		"PopularTags":     s.getPopularTags(),
 		"CategorizedTags": s.getCategorizedTags(),
@@ -203,6 +424,367 @@ func (s *Server) TagHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// tagCrumb is one level of the breadcrumb trail on a tag page
+type tagCrumb struct {
+	Name  string
+	Label string
+}
+
+// tagBreadcrumbs returns the trail from the top-level namespace down to tag,
+// labelled with the levels of its display name
+func tagBreadcrumbs(tag, displayName string) []tagCrumb {
+	names := taxonomy.Ancestors(tag)
+	labels := taxonomy.Ancestors(displayName)
+	if len(labels) != len(names) {
+		labels = names
+	}
+	var crumbs []tagCrumb
+	for i, name := range names {
+		crumbs = append(crumbs, tagCrumb{Name: name, Label: taxonomy.Leaf(labels[i])})
+	}
+	return crumbs
+}
+
+// TagsHandler serves the /tags index of every tag, grouped by category
+func (s *Server) TagsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/tags" && r.URL.Path != "/tags/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	groups, err := s.store.GetCategorizedTags(0)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		log.Printf("Error loading tags: %v", err)
+		return
+	}
+
+	total := 0
+	for _, g := range groups {
+		total += len(g.Tags)
+	}
+
+	data := map[string]interface{}{
+		"Title":           "Tags",
+		"SiteTitle":       s.cfg.SiteTitle,
+		"Description":     fmt.Sprintf("All %d tags", total),
+		"CanonicalURL":    s.cfg.SiteBaseURL + "/tags",
+		"BaseURL":         s.cfg.SiteBaseURL,
+		"IsPost":          false,
+		"Groups":          groups,
+		"TagCount":        total,
+		"PopularTags":     s.getPopularTags(),
+		"CategorizedTags": s.getCategorizedTags(),
+		"ActiveTag":       "",
+	}
+
+	// Set Content-Type header for HTML
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if contentHTML, err := s.view.RenderString("pages/tags.content", data); err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		log.Printf("Template error: %v", err)
+		return
+	} else {
+		data["Content"] = template.HTML(contentHTML)
+	}
+	if err := s.view.Execute(w, "base", data); err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		log.Printf("Template error: %v", err)
+	}
+}
+
+// SeriesHandler serves series index pages at /series/{name}
+func (s *Server) SeriesHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/series/")
+	if name == "" || name == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+
+	parts, err := s.store.GetSeries(name)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		log.Printf("Error fetching series %s: %v", name, err)
+		return
+	}
+	if len(parts) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Title":           fmt.Sprintf("Series: %s", name),
+		"SiteTitle":       s.cfg.SiteTitle,
+		"Description":     fmt.Sprintf("All %d parts of %s", len(parts), name),
+		"CanonicalURL":    s.cfg.SiteBaseURL + "/series/" + url.PathEscape(name),
+		"BaseURL":         s.cfg.SiteBaseURL,
+		"IsPost":          false,
+		"Series":          name,
+		"Posts":           parts,
+		"PopularTags":     s.getPopularTags(),
+		"CategorizedTags": s.getCategorizedTags(),
+		"ActiveTag":       "",
+	}
+
+	// Set Content-Type header for HTML
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if contentHTML, err := s.view.RenderString("pages/series.content", data); err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		log.Printf("Template error: %v", err)
+		return
+	} else {
+		data["Content"] = template.HTML(contentHTML)
+	}
+	if err := s.view.Execute(w, "base", data); err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		log.Printf("Template error: %v", err)
+	}
+}
+
+// ArchiveHandler serves the /archive index of post counts per month
+func (s *Server) ArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/archive" && r.URL.Path != "/archive/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	years, err := s.store.GetArchive(s.loc)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		log.Printf("Error loading archive: %v", err)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Title":           "Archive",
+		"SiteTitle":       s.cfg.SiteTitle,
+		"Description":     "All posts by month",
+		"CanonicalURL":    s.cfg.SiteBaseURL + "/archive",
+		"BaseURL":         s.cfg.SiteBaseURL,
+		"IsPost":          false,
+		"Years":           years,
+		"PopularTags":     s.getPopularTags(),
+		"CategorizedTags": s.getCategorizedTags(),
+		"ActiveTag":       "",
+	}
+
+	// Set Content-Type header for HTML
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if contentHTML, err := s.view.RenderString("pages/archive.content", data); err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		log.Printf("Template error: %v", err)
+		return
+	} else {
+		data["Content"] = template.HTML(contentHTML)
+	}
+	if err := s.view.Execute(w, "base", data); err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		log.Printf("Template error: %v", err)
+	}
+}
+
+// parseArchivePath recognises /{yyyy}/ and /{yyyy}/{mm}/, with or without
+// the trailing slash. month is 0 for a year page.
+func parseArchivePath(urlPath string) (year int, month time.Month, ok bool) {
+	parts := strings.Split(strings.Trim(urlPath, "/"), "/")
+	if len(parts) > 2 || len(parts[0]) != 4 {
+		return 0, 0, false
+	}
+
+	year, err := strconv.Atoi(parts[0])
+	if err != nil || year < 1 {
+		return 0, 0, false
+	}
+	if len(parts) == 1 {
+		return year, 0, true
+	}
+
+	if len(parts[1]) != 2 {
+		return 0, 0, false
+	}
+	m, err := strconv.Atoi(parts[1])
+	if err != nil || m < 1 || m > 12 {
+		return 0, 0, false
+	}
+	return year, time.Month(m), true
+}
+
+// archivePath is the canonical URL path of a year or month archive page
+func archivePath(year int, month time.Month) string {
+	if month == 0 {
+		return fmt.Sprintf("/%04d/", year)
+	}
+	return fmt.Sprintf("/%04d/%02d/", year, month)
+}
+
+// ArchivePeriodHandler serves the posts of one year or month. It is reached
+// through HomeHandler's catch-all.
+func (s *Server) ArchivePeriodHandler(w http.ResponseWriter, r *http.Request, year int, month time.Month) {
+	canonical := archivePath(year, month)
+	if r.URL.Path != canonical {
+		http.Redirect(w, r, canonical, http.StatusMovedPermanently)
+		return
+	}
+
+	posts, err := s.store.GetPostsByPeriod(year, month, s.loc)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		log.Printf("Error fetching posts for %s: %v", canonical, err)
+		return
+	}
+	if len(posts) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	period := strconv.Itoa(year)
+	if month != 0 {
+		period = fmt.Sprintf("%s %d", month, year)
+	}
+
+	data := map[string]interface{}{
+		"Title":           fmt.Sprintf("Archive: %s", period),
+		"SiteTitle":       s.cfg.SiteTitle,
+		"Description":     fmt.Sprintf("Posts from %s", period),
+		"CanonicalURL":    s.cfg.SiteBaseURL + canonical,
+		"BaseURL":         s.cfg.SiteBaseURL,
+		"IsPost":          false,
+		"Period":          period,
+		"Year":            year,
+		"IsMonth":         month != 0,
+		"Posts":           posts,
+		"PopularTags":     s.getPopularTags(),
+		"CategorizedTags": s.getCategorizedTags(),
+		"ActiveTag":       "",
+	}
+
+	// Set Content-Type header for HTML
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if contentHTML, err := s.view.RenderString("pages/archive_period.content", data); err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		log.Printf("Template error: %v", err)
+		return
+	} else {
+		data["Content"] = template.HTML(contentHTML)
+	}
+	if err := s.view.Execute(w, "base", data); err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		log.Printf("Template error: %v", err)
+	}
+}
+
+// SectionHandler serves section index pages at /{section}/ and their feeds
+// at /{section}/feed.xml. It is reached through HomeHandler's catch-all.
+func (s *Server) SectionHandler(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	name := parts[0]
+	if name == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	section, err := s.store.GetSection(name)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		log.Printf("Error getting section %s: %v", name, err)
+		return
+	}
+	if section == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		http.Redirect(w, r, "/"+name+"/", http.StatusMovedPermanently)
+		return
+	case parts[1] == "feed.xml":
+		s.sectionFeed(w, section)
+		return
+	case parts[1] != "":
+		http.NotFound(w, r)
+		return
+	}
+
+	posts, err := s.store.GetPostsBySection(section.Name, s.cfg.IsDev())
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		log.Printf("Error fetching posts for section %s: %v", section.Name, err)
+		return
+	}
+
+	description := section.Summary
+	if description == "" {
+		description = fmt.Sprintf("Posts in %s", section.Title)
+	}
+
+	data := map[string]interface{}{
+		"Title":           section.Title,
+		"SiteTitle":       s.cfg.SiteTitle,
+		"Description":     description,
+		"CanonicalURL":    s.cfg.SiteBaseURL + "/" + section.Name + "/",
+		"BaseURL":         s.cfg.SiteBaseURL,
+		"IsPost":          false,
+		"Section":         section,
+		"Intro":           template.HTML(section.IntroHTML),
+		"Posts":           posts,
+		"PopularTags":     s.getPopularTags(),
+		"CategorizedTags": s.getCategorizedTags(),
+		"ActiveTag":       "",
+	}
+
+	// Sections may override the generic listing with sections/{name}.content
+	page := "pages/section.content"
+	if custom := "sections/" + section.Name + ".content"; s.view.Has(custom) {
+		page = custom
+	}
+
+	// Set Content-Type header for HTML
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if contentHTML, err := s.view.RenderString(page, data); err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		log.Printf("Template error: %v", err)
+		return
+	} else {
+		data["Content"] = template.HTML(contentHTML)
+	}
+	if err := s.view.Execute(w, "base", data); err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		log.Printf("Template error: %v", err)
+	}
+}
+
+// sectionFeed writes the Atom feed for a single section
+func (s *Server) sectionFeed(w http.ResponseWriter, section *store.Section) {
+	posts, err := s.store.GetPostsBySection(section.Name, s.cfg.IsDev())
+	if err != nil {
+		http.Error(w, "Failed to load posts for feed", http.StatusInternalServerError)
+		log.Printf("Error loading posts for section feed %s: %v", section.Name, err)
+		return
+	}
+
+	atomXML, err := feed.GenerateAtomFeed(posts, s.cfg, feed.FeedInfo{
+		Title: s.cfg.SiteTitle + " - " + section.Title,
+		Path:  "/" + section.Name + "/",
+	})
+	if err != nil {
+		http.Error(w, "Failed to generate feed", http.StatusInternalServerError)
+		log.Printf("Error generating section feed %s: %v", section.Name, err)
+		return
+	}
+
+	// Set headers
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600") // Cache for 1 hour
+	w.WriteHeader(http.StatusOK)
+	w.Write(atomXML)
+}
+
 // StaticHandler serves static assets
 func (s *Server) StaticHandler(w http.ResponseWriter, r *http.Request) {
 	// Serve from internal/view/assets when file exists; otherwise allow CSS placeholder
@@ -291,25 +873,53 @@ func (s *Server) SitemapHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(sitemapXML)
 }
 
-// ChromaCSSHandler serves the CSS for syntax highlighting
-func (s *Server) ChromaCSSHandler(w http.ResponseWriter, r *http.Request) {
-	renderer := content.NewRenderer()
-	css, err := renderer.GetStyle()
+// GraphHandler serves the graph of published posts, tags and the links
+// between them as JSON for a client-side graph view
+func (s *Server) GraphHandler(w http.ResponseWriter, r *http.Request) {
+	graph, err := s.store.GetGraph()
 	if err != nil {
-		http.Error(w, "Failed to generate CSS", http.StatusInternalServerError)
-		log.Printf("Error generating Chroma CSS: %v", err)
+		http.Error(w, "Failed to load graph", http.StatusInternalServerError)
+		log.Printf("Error loading graph: %v", err)
 		return
 	}
 
-	// Set headers
-	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.Header().Set("Content-Type", "application/json")
 	if s.cfg.IsDev() {
 		w.Header().Set("Cache-Control", "no-store, max-age=0")
 	} else {
-		w.Header().Set("Cache-Control", "public, max-age=86400") // Cache for 24 hours
+		w.Header().Set("Cache-Control", "public, max-age=3600") // Cache for 1 hour
 	}
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(css))
+	_ = json.NewEncoder(w).Encode(graph)
+}
+
+// ChromaCSSHandler serves the CSS for syntax highlighting: the configured
+// style, and its dark counterpart under prefers-color-scheme. The stylesheet
+// is built once and revalidated with its ETag.
+func (s *Server) ChromaCSSHandler(w http.ResponseWriter, r *http.Request) {
+	s.chromaOnce.Do(s.buildChromaCSS)
+
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.Header().Set("ETag", s.chromaETag)
+	if s.cfg.IsDev() {
+		w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=86400") // Cache for 24 hours
+	}
+	http.ServeContent(w, r, "chroma.css", time.Time{}, bytes.NewReader(s.chromaCSS))
+}
+
+// buildChromaCSS renders the highlighting stylesheet, falling back to the
+// default styles when the configured ones are unknown
+func (s *Server) buildChromaCSS() {
+	css, err := content.HighlightCSS(s.cfg.HighlightStyle, s.cfg.HighlightStyleDark)
+	if err != nil {
+		log.Printf("Invalid highlight style, using defaults: %v", err)
+		css, _ = content.HighlightCSS(content.DefaultHighlightStyle, content.DefaultHighlightDarkStyle)
+	}
+	sum := sha256.Sum256([]byte(css))
+	s.chromaCSS = []byte(css)
+	s.chromaETag = `"` + hex.EncodeToString(sum[:8]) + `"`
 }
 
 // AdminReloadHandler reloads content from the filesystem and upserts into the DB.
@@ -325,14 +935,25 @@ func (s *Server) AdminReloadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Load from content dir
-	loader := content.NewLoader(s.cfg.ContentDir)
-	posts, err := loader.LoadAll()
-	if err != nil {
-		log.Printf("reload: failed to load content: %v", err)
+	// Load from content dir; files that fail are reported rather than aborting the reload
+	site, err := content.NewLoaderFromConfig(s.cfg).LoadSite()
+	loadErrs := content.AsLoadErrors(err)
+	if err != nil && loadErrs == nil {
+		log.Printf("reload: %v", err)
 		http.Error(w, "failed to load content", http.StatusInternalServerError)
 		return
 	}
+	s.store.SetTaxonomy(site.Taxonomy)
+	posts, sections := site.Posts, site.Sections
+
+	for _, le := range loadErrs {
+		if le.Warning {
+			log.Printf("reload: %v", le)
+		} else {
+			log.Printf("reload: skipped %v", le)
+		}
+	}
+	s.SetLoadErrors(loadErrs)
 
 	// Upsert
 	if err := s.store.UpsertPosts(posts); err != nil {
@@ -340,9 +961,64 @@ func (s *Server) AdminReloadHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "failed to upsert posts", http.StatusInternalServerError)
 		return
 	}
+	if err := s.store.UpsertSections(sections); err != nil {
+		log.Printf("reload: failed to upsert sections: %v", err)
+		http.Error(w, "failed to upsert sections", http.StatusInternalServerError)
+		return
+	}
 
 	// Response
+	status := "ok"
+	if len(loadErrs.Failed()) > 0 {
+		status = "partial"
+	}
+	if loadErrs == nil {
+		loadErrs = content.LoadErrors{}
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte(fmt.Sprintf(`{"status":"ok","reloaded":%d}`, len(posts))))
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   status,
+		"reloaded": len(posts),
+		"errors":   loadErrs,
+	})
+}
+
+// ErrorsHandler shows the content load errors as an overlay page. Dev only.
+func (s *Server) ErrorsHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.cfg.IsDev() {
+		http.NotFound(w, r)
+		return
+	}
+
+	loadErrs := s.LoadErrors()
+	data := map[string]interface{}{
+		"Title":           "Content errors",
+		"SiteTitle":       s.cfg.SiteTitle,
+		"Description":     "Content files that failed to load",
+		"CanonicalURL":    s.cfg.SiteBaseURL + "/admin/errors",
+		"BaseURL":         s.cfg.SiteBaseURL,
+		"IsPost":          false,
+		"LoadErrors":      loadErrs,
+		"ContentDir":      s.cfg.ContentDir,
+		"PopularTags":     s.getPopularTags(),
+		"CategorizedTags": s.getCategorizedTags(),
+		"ActiveTag":       "",
+	}
+
+	// Set Content-Type header for HTML
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+
+	if contentHTML, err := s.view.RenderString("pages/errors.content", data); err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		log.Printf("Template error: %v", err)
+		return
+	} else {
+		data["Content"] = template.HTML(contentHTML)
+	}
+	if err := s.view.Execute(w, "base", data); err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		log.Printf("Template error: %v", err)
+	}
 }
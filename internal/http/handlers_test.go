@@ -1,13 +1,20 @@
 package http
 
 import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"notebook.oceanheart.ai/internal/config"
 	"notebook.oceanheart.ai/internal/store"
+	"notebook.oceanheart.ai/internal/taxonomy"
 )
 
 func TestHomeHandler(t *testing.T) {
@@ -199,14 +206,14 @@ func TestTagHandler(t *testing.T) {
 		t.Error("Expected posts tagged heading")
 	}
 
-	// Test invalid tag path
+	// Empty tag path redirects to the tag index
 	req = httptest.NewRequest("GET", "/tag/", nil)
 	w = httptest.NewRecorder()
 
 	server.TagHandler(w, req)
 
-	if w.Code != http.StatusNotFound {
-		t.Errorf("Expected status 404 for empty tag, got %d", w.Code)
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/tags" {
+		t.Errorf("Expected 301 to /tags for empty tag, got %d %s", w.Code, w.Header().Get("Location"))
 	}
 }
 
@@ -517,6 +524,921 @@ func TestSitemapHandler(t *testing.T) {
 	}
 }
 
+func TestAdminReloadReportsErrors(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "reload.db"))
+	defer db.Close()
+
+	contentDir := t.TempDir()
+	valid := "---\ntitle: \"Valid\"\ndate: \"2025-09-12\"\n---\n\nBody.\n"
+	broken := "---\ntitle: \"Broken\"\ndate: \"yesterday\"\n---\n\nBody.\n"
+	if err := os.WriteFile(filepath.Join(contentDir, "valid.md"), []byte(valid), 0644); err != nil {
+		t.Fatalf("Failed to write valid.md: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(contentDir, "broken.md"), []byte(broken), 0644); err != nil {
+		t.Fatalf("Failed to write broken.md: %v", err)
+	}
+
+	cfg := &config.Config{
+		SiteTitle:   "Test Blog",
+		Environment: "dev",
+		ContentDir:  contentDir,
+	}
+	server := NewServer(db, cfg)
+
+	req := httptest.NewRequest("POST", "/admin/reload", nil)
+	w := httptest.NewRecorder()
+	server.AdminReloadHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for partial reload, got %d", w.Code)
+	}
+
+	var resp struct {
+		Status   string `json:"status"`
+		Reloaded int    `json:"reloaded"`
+		Errors   []struct {
+			Path   string `json:"path"`
+			Line   int    `json:"line"`
+			Reason string `json:"reason"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode reload response: %v", err)
+	}
+	if resp.Status != "partial" {
+		t.Errorf("Expected status 'partial', got %s", resp.Status)
+	}
+	if resp.Reloaded != 1 {
+		t.Errorf("Expected 1 reloaded post, got %d", resp.Reloaded)
+	}
+	if len(resp.Errors) != 1 || filepath.Base(resp.Errors[0].Path) != "broken.md" || resp.Errors[0].Line != 3 {
+		t.Errorf("Expected broken.md:3 to be reported, got %+v", resp.Errors)
+	}
+
+	// The dev overlay lists the same failures
+	req = httptest.NewRequest("GET", "/admin/errors", nil)
+	w = httptest.NewRecorder()
+	server.ErrorsHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for errors page, got %d", w.Code)
+	}
+	if !contains(w.Body.String(), "broken.md:3") {
+		t.Error("Expected errors page to list broken.md:3")
+	}
+
+	// Hidden outside dev
+	cfg.Environment = "prod"
+	w = httptest.NewRecorder()
+	server.ErrorsHandler(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for errors page in prod, got %d", w.Code)
+	}
+}
+
+func TestSectionHandler(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "section.db"))
+	defer db.Close()
+
+	cfg := &config.Config{
+		SiteTitle:   "Test Blog",
+		SiteBaseURL: "https://example.com",
+		Environment: "test",
+	}
+	server := NewServer(db, cfg)
+
+	posts := []*store.Post{
+		{Slug: "notebook", Title: "Notebook Engine", HTML: "<p>n</p>", RawMD: "n", PublishedAt: "2025-09-17T00:00:00Z", UpdatedAt: "2025-09-17T00:00:00Z", Section: "projects"},
+		{Slug: "welcome", Title: "Welcome", HTML: "<p>w</p>", RawMD: "w", PublishedAt: "2025-09-12T00:00:00Z", UpdatedAt: "2025-09-12T00:00:00Z"},
+	}
+	if err := db.UpsertPosts(posts); err != nil {
+		t.Fatalf("Failed to insert posts: %v", err)
+	}
+	if err := db.UpsertSections([]*store.Section{{Name: "projects", Title: "Projects", IntroHTML: "<p>Things I built.</p>"}}); err != nil {
+		t.Fatalf("Failed to insert section: %v", err)
+	}
+
+	// Section index, reached through the root handler
+	req := httptest.NewRequest("GET", "/projects/", nil)
+	w := httptest.NewRecorder()
+	server.HomeHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !contains(body, "Things I built.") {
+		t.Error("Expected section intro")
+	}
+	if !contains(body, "Notebook Engine") || contains(body, "Welcome") {
+		t.Error("Expected only the section's posts")
+	}
+
+	// Missing trailing slash redirects
+	req = httptest.NewRequest("GET", "/projects", nil)
+	w = httptest.NewRecorder()
+	server.HomeHandler(w, req)
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/projects/" {
+		t.Errorf("Expected redirect to /projects/, got %d %s", w.Code, w.Header().Get("Location"))
+	}
+
+	// Section feed
+	req = httptest.NewRequest("GET", "/projects/feed.xml", nil)
+	w = httptest.NewRecorder()
+	server.HomeHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for section feed, got %d", w.Code)
+	}
+	if !contains(w.Body.String(), `<link href="https://example.com/projects/feed.xml" rel="self"`) {
+		t.Error("Expected section feed self link")
+	}
+
+	// Unknown section
+	req = httptest.NewRequest("GET", "/nope/", nil)
+	w = httptest.NewRecorder()
+	server.HomeHandler(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown section, got %d", w.Code)
+	}
+}
+
+func TestPostHandlerServesBundleAssets(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "bundle.db"))
+	defer db.Close()
+
+	contentDir := t.TempDir()
+	bundle := filepath.Join(contentDir, "2025-09-17-notebook")
+	if err := os.MkdirAll(bundle, 0755); err != nil {
+		t.Fatalf("Failed to create bundle: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(bundle, "diagram.png"), []byte("\x89PNG\r\n\x1a\n"), 0644); err != nil {
+		t.Fatalf("Failed to write asset: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(bundle, "index.md"), []byte("# source"), 0644); err != nil {
+		t.Fatalf("Failed to write index.md: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(bundle, "v1..2.png"), []byte("\x89PNG\r\n\x1a\n"), 0644); err != nil {
+		t.Fatalf("Failed to write asset: %v", err)
+	}
+
+	cfg := &config.Config{
+		SiteTitle:   "Test Blog",
+		Environment: "prod",
+		ContentDir:  contentDir,
+	}
+	server := NewServer(db, cfg)
+
+	post := &store.Post{
+		Slug:        "notebook",
+		Title:       "Notebook",
+		HTML:        `<p><img src="/p/notebook/diagram.png" alt=""></p>`,
+		RawMD:       "![](diagram.png)",
+		PublishedAt: "2025-09-17T00:00:00Z",
+		UpdatedAt:   "2025-09-17T00:00:00Z",
+		BundleDir:   "2025-09-17-notebook",
+	}
+	if err := db.UpsertPost(post); err != nil {
+		t.Fatalf("Failed to insert post: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/p/notebook/diagram.png", nil)
+	w := httptest.NewRecorder()
+	server.PostHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for bundle asset, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Expected image/png, got %s", ct)
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "public, max-age=86400" {
+		t.Errorf("Expected day-long caching, got %s", cc)
+	}
+
+	// Dots inside a name are not a parent directory
+	req = httptest.NewRequest("GET", "/p/notebook/v1..2.png", nil)
+	w = httptest.NewRecorder()
+	server.PostHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for v1..2.png, got %d", w.Code)
+	}
+
+	for _, path := range []string{"/p/notebook/index.md", "/p/notebook/missing.png", "/p/notebook/../../etc/passwd"} {
+		req = httptest.NewRequest("GET", path, nil)
+		w = httptest.NewRecorder()
+		server.PostHandler(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for %s, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestImageHandler(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "images.db"))
+	defer db.Close()
+
+	contentDir := t.TempDir()
+	bundle := filepath.Join(contentDir, "2025-09-17-notebook")
+	if err := os.MkdirAll(bundle, 0755); err != nil {
+		t.Fatalf("Failed to create bundle: %v", err)
+	}
+	f, err := os.Create(filepath.Join(bundle, "diagram.png"))
+	if err != nil {
+		t.Fatalf("Failed to create image: %v", err)
+	}
+	if err := png.Encode(f, image.NewNRGBA(image.Rect(0, 0, 1000, 500))); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	f.Close()
+
+	cfg := &config.Config{
+		SiteTitle:     "Test Blog",
+		Environment:   "prod",
+		ContentDir:    contentDir,
+		ImageCacheDir: t.TempDir(),
+	}
+	server := NewServer(db, cfg)
+
+	post := &store.Post{
+		Slug:        "notebook",
+		Title:       "Notebook",
+		HTML:        "<p></p>",
+		RawMD:       "![](diagram.png)",
+		PublishedAt: "2025-09-17T00:00:00Z",
+		UpdatedAt:   "2025-09-17T00:00:00Z",
+		BundleDir:   "2025-09-17-notebook",
+	}
+	if err := db.UpsertPost(post); err != nil {
+		t.Fatalf("Failed to insert post: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/img/notebook/480/diagram.png.webp", nil)
+	w := httptest.NewRecorder()
+	server.ImageHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/webp" {
+		t.Errorf("Expected image/webp, got %s", ct)
+	}
+	if !contains(w.Body.String(), "WEBP") {
+		t.Error("Expected a WebP payload")
+	}
+
+	// Widths outside the configured set are rejected
+	for _, path := range []string{"/img/notebook/123/diagram.png.webp", "/img/missing/480/diagram.png.webp", "/img/notebook/480/other.png.webp"} {
+		req = httptest.NewRequest("GET", path, nil)
+		w = httptest.NewRecorder()
+		server.ImageHandler(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for %s, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestPostHandlerTOC(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "toc.db"))
+	defer db.Close()
+
+	cfg := &config.Config{SiteTitle: "Test Blog", Environment: "prod"}
+	server := NewServer(db, cfg)
+
+	toc := []store.Heading{{Level: 2, ID: "why-passport", Text: "Why Passport"}}
+	posts := []*store.Post{
+		{Slug: "with-toc", Title: "With TOC", HTML: "<h2 id=\"why-passport\">Why Passport</h2>", RawMD: "## Why Passport", PublishedAt: "2025-09-15T00:00:00Z", UpdatedAt: "2025-09-15T00:00:00Z", TOC: toc, ShowTOC: true},
+		{Slug: "without-toc", Title: "Without TOC", HTML: "<h2 id=\"why-passport\">Why Passport</h2>", RawMD: "## Why Passport", PublishedAt: "2025-09-15T00:00:00Z", UpdatedAt: "2025-09-15T00:00:00Z", TOC: toc},
+	}
+	if err := db.UpsertPosts(posts); err != nil {
+		t.Fatalf("Failed to insert posts: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/p/with-toc", nil)
+	w := httptest.NewRecorder()
+	server.PostHandler(w, req)
+	if !contains(w.Body.String(), `<a href="#why-passport">Why Passport</a>`) {
+		t.Error("Expected table of contents link when toc: true")
+	}
+
+	req = httptest.NewRequest("GET", "/p/without-toc", nil)
+	w = httptest.NewRecorder()
+	server.PostHandler(w, req)
+	if contains(w.Body.String(), `class="toc"`) {
+		t.Error("Expected no table of contents without toc: true")
+	}
+}
+
+func TestListingsFallBackToExcerpt(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "excerpt.db"))
+	defer db.Close()
+
+	cfg := &config.Config{SiteTitle: "Test Blog", SiteBaseURL: "https://example.com", Environment: "prod"}
+	server := NewServer(db, cfg)
+
+	post := &store.Post{
+		Slug:        "no-summary",
+		Title:       "No Summary",
+		HTML:        "<p>The opening paragraph.</p>",
+		RawMD:       "The opening paragraph.",
+		PublishedAt: "2025-09-15T00:00:00Z",
+		UpdatedAt:   "2025-09-15T00:00:00Z",
+		WordCount:   3,
+		ReadingTime: 1,
+		Excerpt:     "The opening paragraph.",
+	}
+	if err := db.UpsertPost(post); err != nil {
+		t.Fatalf("Failed to insert post: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	server.HomeHandler(w, req)
+	body := w.Body.String()
+	if !contains(body, `<div class="summary">The opening paragraph.</div>`) {
+		t.Error("Expected home listing to show the excerpt")
+	}
+	if !contains(body, "1 min read") {
+		t.Error("Expected home listing to show reading time")
+	}
+
+	req = httptest.NewRequest("GET", "/p/no-summary", nil)
+	w = httptest.NewRecorder()
+	server.PostHandler(w, req)
+	if !contains(w.Body.String(), `<meta name="description" content="The opening paragraph.">`) {
+		t.Error("Expected meta description to use the excerpt")
+	}
+}
+
+func TestPostHandlerTagsAndRelated(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "related.db"))
+	defer db.Close()
+
+	cfg := &config.Config{SiteTitle: "Test Blog", Environment: "prod"}
+	server := NewServer(db, cfg)
+
+	posts := []*store.Post{
+		{Slug: "first", Title: "First", HTML: "<p>First</p>", RawMD: "First", PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-01T00:00:00Z", Tags: []string{"go", "sqlite"}},
+		{Slug: "second", Title: "Second", HTML: "<p>Second</p>", RawMD: "Second", PublishedAt: "2025-09-02T00:00:00Z", UpdatedAt: "2025-09-02T00:00:00Z", Tags: []string{"sqlite"}},
+	}
+	if err := db.UpsertPosts(posts); err != nil {
+		t.Fatalf("Failed to insert posts: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/p/first", nil)
+	w := httptest.NewRecorder()
+	server.PostHandler(w, req)
+
+	body := w.Body.String()
+	if !contains(body, `<a href="/tag/go" class="tag">go</a>`) {
+		t.Error("Expected post page to link its tags")
+	}
+	if !contains(body, "Related posts") || !contains(body, `<a href="/p/second">Second</a>`) {
+		t.Error("Expected post page to list related posts")
+	}
+}
+
+func TestSeriesNavigation(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "series.db"))
+	defer db.Close()
+
+	cfg := &config.Config{SiteTitle: "Test Blog", Environment: "prod"}
+	server := NewServer(db, cfg)
+
+	posts := []*store.Post{
+		{Slug: "part-one", Title: "Part One", HTML: "<p>1</p>", RawMD: "1", PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-01T00:00:00Z", Series: "Deep Dive", SeriesOrder: 1},
+		{Slug: "part-two", Title: "Part Two", HTML: "<p>2</p>", RawMD: "2", PublishedAt: "2025-09-02T00:00:00Z", UpdatedAt: "2025-09-02T00:00:00Z", Series: "Deep Dive", SeriesOrder: 2},
+		{Slug: "later", Title: "Later", HTML: "<p>3</p>", RawMD: "3", PublishedAt: "2025-09-03T00:00:00Z", UpdatedAt: "2025-09-03T00:00:00Z"},
+	}
+	if err := db.UpsertPosts(posts); err != nil {
+		t.Fatalf("Failed to insert posts: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/p/part-two", nil)
+	w := httptest.NewRecorder()
+	server.PostHandler(w, req)
+
+	body := w.Body.String()
+	if !contains(body, "Part 2 of 2 in") || !contains(body, `<a href="/series/Deep%20Dive">Deep Dive</a>`) {
+		t.Error("Expected series box with part number and series link")
+	}
+	if !contains(body, `<a class="prev" href="/p/part-one" rel="prev">`) {
+		t.Error("Expected previous post link")
+	}
+	if !contains(body, `<a class="next" href="/p/later" rel="next">`) {
+		t.Error("Expected next post link")
+	}
+
+	req = httptest.NewRequest("GET", "/series/Deep%20Dive", nil)
+	w = httptest.NewRecorder()
+	server.SeriesHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for series page, got %d", w.Code)
+	}
+	body = w.Body.String()
+	if strings.Index(body, "Part One") > strings.Index(body, "Part Two") {
+		t.Error("Expected series page to list parts in order")
+	}
+
+	req = httptest.NewRequest("GET", "/series/missing", nil)
+	w = httptest.NewRecorder()
+	server.SeriesHandler(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown series, got %d", w.Code)
+	}
+}
+
+func TestArchiveHandlers(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "archive.db"))
+	defer db.Close()
+
+	cfg := &config.Config{SiteTitle: "Test Blog", Environment: "prod"}
+	server := NewServer(db, cfg)
+
+	posts := []*store.Post{
+		{Slug: "september", Title: "September Post", HTML: "<p>1</p>", RawMD: "1", PublishedAt: "2025-09-12T10:00:00Z", UpdatedAt: "2025-09-12T10:00:00Z"},
+		{Slug: "october", Title: "October Post", HTML: "<p>2</p>", RawMD: "2", PublishedAt: "2025-10-02T10:00:00Z", UpdatedAt: "2025-10-02T10:00:00Z"},
+	}
+	if err := db.UpsertPosts(posts); err != nil {
+		t.Fatalf("Failed to insert posts: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/archive", nil)
+	w := httptest.NewRecorder()
+	server.ArchiveHandler(w, req)
+	body := w.Body.String()
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for archive, got %d", w.Code)
+	}
+	if !contains(body, `<a href="/2025/09/">September</a> <span class="count">1</span>`) {
+		t.Error("Expected archive to link months with counts")
+	}
+	if !contains(body, `<a href="/2025/">2025</a> <span class="count">2</span>`) {
+		t.Error("Expected archive to link years with counts")
+	}
+
+	tests := []struct {
+		path     string
+		status   int
+		contains string
+		location string
+	}{
+		{"/2025/", http.StatusOK, "October Post", ""},
+		{"/2025/09/", http.StatusOK, "Posts from September 2025", ""},
+		{"/2025/09", http.StatusMovedPermanently, "", "/2025/09/"},
+		{"/2025", http.StatusMovedPermanently, "", "/2025/"},
+		{"/2024/", http.StatusNotFound, "", ""},
+		{"/2025/13/", http.StatusNotFound, "", ""},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.path, nil)
+		w := httptest.NewRecorder()
+		server.HomeHandler(w, req)
+
+		if w.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d", test.path, test.status, w.Code)
+		}
+		if test.contains != "" && !contains(w.Body.String(), test.contains) {
+			t.Errorf("%s: expected body to contain %q", test.path, test.contains)
+		}
+		if test.location != "" && w.Header().Get("Location") != test.location {
+			t.Errorf("%s: expected redirect to %s, got %s", test.path, test.location, w.Header().Get("Location"))
+		}
+	}
+
+	// The month page lists only that month
+	req = httptest.NewRequest("GET", "/2025/09/", nil)
+	w = httptest.NewRecorder()
+	server.HomeHandler(w, req)
+	if contains(w.Body.String(), "October Post") {
+		t.Error("Expected September page to exclude October posts")
+	}
+}
+
+func TestTagsHandler(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "tags.db"))
+	defer db.Close()
+
+	cfg := &config.Config{SiteTitle: "Test Blog", Environment: "prod"}
+	server := NewServer(db, cfg)
+
+	// More tags than the navigation shows, with uneven use
+	var posts []*store.Post
+	for i := 0; i < 12; i++ {
+		tags := []string{fmt.Sprintf("topic-%02d", i)}
+		if i < 8 {
+			tags = append(tags, "go")
+		}
+		posts = append(posts, &store.Post{
+			Slug:        fmt.Sprintf("post-%d", i),
+			Title:       fmt.Sprintf("Post %d", i),
+			HTML:        "<p></p>",
+			PublishedAt: "2025-09-01T00:00:00Z",
+			UpdatedAt:   "2025-09-01T00:00:00Z",
+			Tags:        tags,
+		})
+	}
+	if err := db.UpsertPosts(posts); err != nil {
+		t.Fatalf("Failed to insert posts: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/tags", nil)
+	w := httptest.NewRecorder()
+	server.TagsHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !contains(body, `<a href="/tag/topic-11" class="tag weight-1">topic-11 <span class="count">1</span></a>`) {
+		t.Error("Expected tags beyond the navigation limit with the smallest weight")
+	}
+	if !contains(body, `<a href="/tag/go" class="tag weight-5">go <span class="count">8</span></a>`) {
+		t.Error("Expected the most used tag with the largest weight")
+	}
+	if !contains(body, `<h3>Technical</h3>`) {
+		t.Error("Expected tags grouped by category")
+	}
+}
+
+func TestTaxonomyAliasesAndColours(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "taxonomy.db"))
+	defer db.Close()
+
+	tax, err := taxonomy.Parse([]byte(`
+categories:
+  - name: languages
+    title: Languages
+    color: "#0066cc"
+    match:
+      tags: [go]
+aliases:
+  golang: go
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	db.SetTaxonomy(tax)
+
+	cfg := &config.Config{SiteTitle: "Test Blog", Environment: "prod"}
+	server := NewServer(db, cfg)
+
+	post := &store.Post{Slug: "p", Title: "P", HTML: "<p></p>", PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-01T00:00:00Z", Tags: []string{"go", "meta"}}
+	if err := db.UpsertPosts([]*store.Post{post}); err != nil {
+		t.Fatalf("Failed to insert post: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/tag/golang", nil)
+	w := httptest.NewRecorder()
+	server.TagHandler(w, req)
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/tag/go" {
+		t.Errorf("Expected alias to redirect to /tag/go, got %d %s", w.Code, w.Header().Get("Location"))
+	}
+
+	req = httptest.NewRequest("GET", "/tags", nil)
+	w = httptest.NewRecorder()
+	server.TagsHandler(w, req)
+	body := w.Body.String()
+	if !contains(body, `class="tag-link tag-languages " style="color: #0066cc;"`) {
+		t.Error("Expected navigation tag coloured by its category")
+	}
+	if strings.Index(body, "<h3>Languages</h3>") > strings.Index(body, "<h3>General</h3>") {
+		t.Error("Expected categories in taxonomy order")
+	}
+}
+
+func TestNamespacedTagHandler(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "namespaced.db"))
+	defer db.Close()
+
+	cfg := &config.Config{SiteTitle: "Test Blog", Environment: "prod"}
+	server := NewServer(db, cfg)
+
+	posts := []*store.Post{
+		{Slug: "analysis-post", Title: "Analysis Post", HTML: "<p></p>",
+			PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-01T00:00:00Z",
+			Tags: []string{"cognitive-skill:analysis"}},
+		{Slug: "synthesis-post", Title: "Synthesis Post", HTML: "<p></p>",
+			PublishedAt: "2025-09-02T00:00:00Z", UpdatedAt: "2025-09-02T00:00:00Z",
+			Tags: []string{"cognitive-skill:synthesis"}},
+	}
+	if err := db.UpsertPosts(posts); err != nil {
+		t.Fatalf("Failed to insert posts: %v", err)
+	}
+
+	// The parent page lists its descendants' posts and links to the subtags
+	req := httptest.NewRequest("GET", "/tag/cognitive-skill", nil)
+	w := httptest.NewRecorder()
+	server.TagHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !contains(body, "Analysis Post") || !contains(body, "Synthesis Post") {
+		t.Error("Expected parent tag page to include child tag posts")
+	}
+	if !contains(body, `href="/tag/cognitive-skill/analysis"`) {
+		t.Error("Expected link to the child tag page")
+	}
+
+	// A child page shows the breadcrumb trail back to its namespace
+	req = httptest.NewRequest("GET", "/tag/cognitive-skill/analysis", nil)
+	w = httptest.NewRecorder()
+	server.TagHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body = w.Body.String()
+	if !contains(body, `<a href="/tag/cognitive-skill">cognitive-skill</a>`) {
+		t.Error("Expected breadcrumb link to the parent tag")
+	}
+	if !contains(body, `<span aria-current="page">analysis</span>`) {
+		t.Error("Expected current breadcrumb level")
+	}
+	if contains(body, "Synthesis Post") {
+		t.Error("Expected child page to exclude sibling posts")
+	}
+
+	// Legacy URLs with the separator spelled out redirect to the path form
+	req = httptest.NewRequest("GET", "/tag/cognitive-skill:analysis", nil)
+	w = httptest.NewRecorder()
+	server.TagHandler(w, req)
+
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/tag/cognitive-skill/analysis" {
+		t.Errorf("Expected 301 to path form, got %d %s", w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestTagHandlerCaseInsensitive(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "case.db"))
+	defer db.Close()
+
+	cfg := &config.Config{SiteTitle: "Test Blog", Environment: "prod"}
+	server := NewServer(db, cfg)
+
+	post := &store.Post{
+		Slug: "sunk", Title: "Sunk Costs", HTML: "<p></p>",
+		PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-01T00:00:00Z",
+		Tags: []string{"Sunk Cost"},
+	}
+	if err := db.UpsertPosts([]*store.Post{post}); err != nil {
+		t.Fatalf("Failed to insert post: %v", err)
+	}
+
+	// Other spellings redirect to the normalised URL
+	for _, path := range []string{"/tag/Sunk%20Cost", "/tag/SUNK-COST"} {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		server.TagHandler(w, req)
+
+		if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/tag/sunk-cost" {
+			t.Errorf("%s: expected 301 to /tag/sunk-cost, got %d %s", path, w.Code, w.Header().Get("Location"))
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/tag/sunk-cost", nil)
+	w := httptest.NewRecorder()
+	server.TagHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !contains(body, "Posts tagged: Sunk Cost") {
+		t.Error("Expected the display name in the heading")
+	}
+	if !contains(body, `<a href="/tag/sunk-cost" class="tag">Sunk Cost</a>`) {
+		t.Error("Expected post tag linked by its normalised name")
+	}
+}
+
+func TestPostHandlerBacklinks(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "backlinks.db"))
+	defer db.Close()
+
+	cfg := &config.Config{SiteTitle: "Test Blog", Environment: "prod"}
+	server := NewServer(db, cfg)
+
+	posts := []*store.Post{
+		{Slug: "target", Title: "Target", HTML: "<p>Target</p>", RawMD: "Target", PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-01T00:00:00Z"},
+		{Slug: "source", Title: "Source", HTML: `<p><a href="/p/target" class="wikilink">target</a></p>`, RawMD: "[[target]]", PublishedAt: "2025-09-02T00:00:00Z", UpdatedAt: "2025-09-02T00:00:00Z", Links: []string{"target"}},
+	}
+	if err := db.UpsertPosts(posts); err != nil {
+		t.Fatalf("Failed to insert posts: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/p/target", nil)
+	w := httptest.NewRecorder()
+	server.PostHandler(w, req)
+
+	body := w.Body.String()
+	if !contains(body, "Referenced by") || !contains(body, `<li><a href="/p/source">Source</a></li>`) {
+		t.Error("Expected post page to list the posts linking to it")
+	}
+
+	req = httptest.NewRequest("GET", "/p/source", nil)
+	w = httptest.NewRecorder()
+	server.PostHandler(w, req)
+	if contains(w.Body.String(), "Referenced by") {
+		t.Error("Expected no backlinks section on a post nothing links to")
+	}
+}
+
+func TestGraphHandler(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "graph.db"))
+	defer db.Close()
+
+	cfg := &config.Config{SiteTitle: "Test Blog", Environment: "prod"}
+	server := NewServer(db, cfg)
+
+	posts := []*store.Post{
+		{Slug: "target", Title: "Target", HTML: "<p>Target</p>", RawMD: "Target", PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-01T00:00:00Z", Tags: []string{"go"}},
+		{Slug: "source", Title: "Source", HTML: `<p><a href="/p/target">target</a></p>`, RawMD: "[target](/p/target)", PublishedAt: "2025-09-02T00:00:00Z", UpdatedAt: "2025-09-02T00:00:00Z", Links: []string{"target"}},
+	}
+	if err := db.UpsertPosts(posts); err != nil {
+		t.Fatalf("Failed to insert posts: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/graph.json", nil)
+	w := httptest.NewRecorder()
+	server.GraphHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON content type, got %s", ct)
+	}
+
+	var graph store.Graph
+	if err := json.Unmarshal(w.Body.Bytes(), &graph); err != nil {
+		t.Fatalf("Failed to decode graph: %v", err)
+	}
+	if len(graph.Nodes) != 3 {
+		t.Errorf("Expected 2 posts and 1 tag, got %+v", graph.Nodes)
+	}
+	want := store.GraphEdge{Source: "post:source", Target: "post:target", Type: store.GraphEdgeLink}
+	found := false
+	for _, e := range graph.Edges {
+		found = found || e == want
+	}
+	if !found {
+		t.Errorf("Expected a link edge from source to target, got %+v", graph.Edges)
+	}
+}
+
+func TestPostHandlerMathAssets(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "math.db"))
+	defer db.Close()
+
+	cfg := &config.Config{SiteTitle: "Test Blog", Environment: "prod"}
+	server := NewServer(db, cfg)
+
+	posts := []*store.Post{
+		{Slug: "formulas", Title: "Formulas", HTML: `<p><span class="math math-inline">\(x\)</span></p>`, RawMD: "$x$", PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-01T00:00:00Z", Math: true},
+		{Slug: "prose", Title: "Prose", HTML: "<p>Prose</p>", RawMD: "Prose", PublishedAt: "2025-09-02T00:00:00Z", UpdatedAt: "2025-09-02T00:00:00Z"},
+	}
+	if err := db.UpsertPosts(posts); err != nil {
+		t.Fatalf("Failed to insert posts: %v", err)
+	}
+
+	for slug, wantMath := range map[string]bool{"formulas": true, "prose": false} {
+		req := httptest.NewRequest("GET", "/p/"+slug, nil)
+		w := httptest.NewRecorder()
+		server.PostHandler(w, req)
+
+		body := w.Body.String()
+		if got := contains(body, `<script defer src="/static/vendor/katex/katex.min.js"></script>`) && contains(body, `<script defer src="/static/math.js"></script>`); got != wantMath {
+			t.Errorf("%s: expected math assets loaded = %v", slug, wantMath)
+		}
+	}
+}
+
+func TestPostHandlerMermaidAssets(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "mermaid.db"))
+	defer db.Close()
+
+	cfg := &config.Config{SiteTitle: "Test Blog", Environment: "prod"}
+	server := NewServer(db, cfg)
+
+	posts := []*store.Post{
+		{Slug: "flow", Title: "Flow", HTML: "<figure class=\"diagram diagram-mermaid\"><pre class=\"mermaid\">graph TD\n  A --&gt; B\n</pre></figure>", RawMD: "```mermaid", PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-01T00:00:00Z", Mermaid: true},
+		{Slug: "prose", Title: "Prose", HTML: "<p>Write <code>&lt;pre class=\"mermaid\"&gt;</code> by hand</p>", RawMD: "Prose", PublishedAt: "2025-09-02T00:00:00Z", UpdatedAt: "2025-09-02T00:00:00Z"},
+	}
+	if err := db.UpsertPosts(posts); err != nil {
+		t.Fatalf("Failed to insert posts: %v", err)
+	}
+
+	for slug, wantMermaid := range map[string]bool{"flow": true, "prose": false} {
+		req := httptest.NewRequest("GET", "/p/"+slug, nil)
+		w := httptest.NewRecorder()
+		server.PostHandler(w, req)
+
+		if got := contains(w.Body.String(), `<script defer src="/static/vendor/mermaid/mermaid.min.js"></script>`); got != wantMermaid {
+			t.Errorf("%s: expected mermaid.js loaded = %v", slug, wantMermaid)
+		}
+	}
+}
+
+func TestChromaCSSHandler(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "chroma.db"))
+	defer db.Close()
+
+	cfg := &config.Config{SiteTitle: "Test Blog", Environment: "prod", HighlightStyle: "monokailight", HighlightStyleDark: "monokai"}
+	server := NewServer(db, cfg)
+
+	req := httptest.NewRequest("GET", "/static/chroma.css", nil)
+	w := httptest.NewRecorder()
+	server.ChromaCSSHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/css; charset=utf-8" {
+		t.Errorf("Expected CSS content type, got %s", ct)
+	}
+	if !contains(w.Body.String(), "@media (prefers-color-scheme: dark)") {
+		t.Errorf("Expected a dark palette, got %s", w.Body.String())
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag")
+	}
+
+	req = httptest.NewRequest("GET", "/static/chroma.css", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	server.ChromaCSSHandler(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for a matching ETag, got %d", w.Code)
+	}
+
+	// Unknown styles fall back to the defaults rather than failing
+	fallback := NewServer(db, &config.Config{Environment: "prod", HighlightStyle: "no-such-style"})
+	w = httptest.NewRecorder()
+	fallback.ChromaCSSHandler(w, httptest.NewRequest("GET", "/static/chroma.css", nil))
+	if w.Code != http.StatusOK || !contains(w.Body.String(), ".chroma") {
+		t.Errorf("Expected the default stylesheet, got %d %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("ETag") == etag {
+		t.Error("Expected a different ETag for different styles")
+	}
+}
+
+func TestPostHandlerCopyButtons(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "copy.db"))
+	defer db.Close()
+
+	posts := []*store.Post{
+		{Slug: "code", Title: "Code", HTML: `<pre class="chroma"><code>x</code></pre>`, RawMD: "```go", PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-01T00:00:00Z", Code: true},
+		{Slug: "prose", Title: "Prose", HTML: "<p>Chroma marks code up as <code>&lt;pre class=\"chroma\"&gt;</code></p>", RawMD: "Prose", PublishedAt: "2025-09-02T00:00:00Z", UpdatedAt: "2025-09-02T00:00:00Z"},
+	}
+	if err := db.UpsertPosts(posts); err != nil {
+		t.Fatalf("Failed to insert posts: %v", err)
+	}
+
+	for _, enabled := range []bool{true, false} {
+		server := NewServer(db, &config.Config{SiteTitle: "Test Blog", Environment: "prod", CodeCopyButtons: enabled})
+		for slug, hasCode := range map[string]bool{"code": true, "prose": false} {
+			req := httptest.NewRequest("GET", "/p/"+slug, nil)
+			w := httptest.NewRecorder()
+			server.PostHandler(w, req)
+
+			if got := contains(w.Body.String(), `<script defer src="/static/code.js"></script>`); got != (enabled && hasCode) {
+				t.Errorf("%s with copy buttons %v: expected code.js loaded = %v", slug, enabled, enabled && hasCode)
+			}
+		}
+	}
+}
+
+func TestPostHandlerUpdatedLabel(t *testing.T) {
+	db := store.MustOpen(filepath.Join(t.TempDir(), "updated.db"))
+	defer db.Close()
+
+	cfg := &config.Config{SiteTitle: "Test Blog", Environment: "prod"}
+	server := NewServer(db, cfg)
+
+	posts := []*store.Post{
+		{Slug: "revised", Title: "Revised", HTML: "<p>Body</p>", RawMD: "Body", PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-20T00:00:00Z"},
+		{Slug: "original", Title: "Original", HTML: "<p>Body</p>", RawMD: "Body", PublishedAt: "2025-09-02T00:00:00Z", UpdatedAt: "2025-09-02T00:00:00Z"},
+	}
+	if err := db.UpsertPosts(posts); err != nil {
+		t.Fatalf("Failed to insert posts: %v", err)
+	}
+
+	for slug, wantLabel := range map[string]bool{"revised": true, "original": false} {
+		req := httptest.NewRequest("GET", "/p/"+slug, nil)
+		w := httptest.NewRecorder()
+		server.PostHandler(w, req)
+
+		if got := contains(w.Body.String(), `<span>Updated <time datetime="2025-09-20`); got != wantLabel {
+			t.Errorf("%s: expected updated label = %v", slug, wantLabel)
+		}
+	}
+}
+
 // Helper function to check if string contains substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) &&
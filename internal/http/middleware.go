@@ -4,7 +4,9 @@ import (
 	"compress/gzip"
 	"io"
 	"log"
+	"mime"
 	"net/http"
+	"path"
 	"strings"
 	"time"
 )
@@ -46,8 +48,12 @@ func GzipMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		// Skip compression for certain content types
+		// Skip compression for certain content types. Handlers usually set the
+		// type after this point, so fall back to the request path's extension.
 		contentType := w.Header().Get("Content-Type")
+		if contentType == "" {
+			contentType = mime.TypeByExtension(path.Ext(r.URL.Path))
+		}
 		if strings.Contains(contentType, "image/") || 
 		   strings.Contains(contentType, "video/") ||
 		   strings.Contains(contentType, "application/zip") {
@@ -217,4 +217,23 @@ func TestChainMiddleware(t *testing.T) {
 	if w.Body.String() != "test" {
 		t.Error("Expected response body to be preserved")
 	}
-}
\ No newline at end of file
+}
+func TestGzipMiddlewareSkipsImages(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG"))
+	})
+
+	req := httptest.NewRequest("GET", "/p/notebook/diagram.png", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	GzipMiddleware(handler).ServeHTTP(w, req)
+
+	if w.Header().Get("Content-Encoding") != "" {
+		t.Error("Expected images to be served uncompressed")
+	}
+	if w.Body.String() != "\x89PNG" {
+		t.Error("Expected image bytes to pass through unchanged")
+	}
+}
@@ -0,0 +1,205 @@
+package imaging
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register GIF decoder
+	_ "image/jpeg" // Register JPEG decoder
+	_ "image/png"  // Register PNG decoder
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Register WebP decoder
+)
+
+// DefaultWidths are the variant widths offered in srcset, in pixels
+var DefaultWidths = []int{480, 960, 1440}
+
+// variantExt is the format every resized variant is encoded to
+const variantExt = ".webp"
+
+// Processor generates resized image variants and caches them on disk
+type Processor struct {
+	cacheDir string
+	widths   []int
+
+	mu sync.Mutex // serialises variant generation
+}
+
+// NewProcessor creates an image processor writing variants under cacheDir.
+// An empty cacheDir falls back to a directory in the system temp dir.
+func NewProcessor(cacheDir string, widths []int) *Processor {
+	if cacheDir == "" {
+		cacheDir = filepath.Join(os.TempDir(), "notebook-img")
+	}
+	if len(widths) == 0 {
+		widths = DefaultWidths
+	}
+	return &Processor{cacheDir: cacheDir, widths: widths}
+}
+
+// IsImage reports whether name has an extension the pipeline can decode
+func IsImage(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp":
+		return true
+	}
+	return false
+}
+
+// Dimensions returns the pixel size of the image at srcPath without decoding it fully
+func Dimensions(srcPath string) (int, int, error) {
+	f, err := os.Open(srcPath)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read image header: %w", err)
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// VariantWidths returns the widths from widths that are narrower than an
+// image originalWidth pixels wide; upscaled variants are never offered
+func VariantWidths(widths []int, originalWidth int) []int {
+	var out []int
+	for _, w := range widths {
+		if w < originalWidth {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// VariantURL returns the URL a resized variant of a bundle image is served from
+func VariantURL(slug, file string, width int) string {
+	return "/img/" + slug + "/" + strconv.Itoa(width) + "/" + file + variantExt
+}
+
+// ParseVariantPath splits a /img/{slug}/{width}/{file}.webp path into its parts
+func ParseVariantPath(urlPath string) (slug string, width int, file string, ok bool) {
+	rest := strings.TrimPrefix(urlPath, "/img/")
+	if rest == urlPath {
+		return "", 0, "", false
+	}
+
+	parts := strings.SplitN(rest, "/", 3)
+	if len(parts) != 3 || parts[0] == "" || !strings.HasSuffix(parts[2], variantExt) {
+		return "", 0, "", false
+	}
+
+	width, err := strconv.Atoi(parts[1])
+	if err != nil || width <= 0 {
+		return "", 0, "", false
+	}
+
+	file = strings.TrimSuffix(parts[2], variantExt)
+	if file == "" || path.Clean(file) != file || strings.HasPrefix(file, "../") || !IsImage(file) {
+		return "", 0, "", false
+	}
+
+	return parts[0], width, file, true
+}
+
+// AllowsWidth reports whether width is one of the processor's variant widths
+func (p *Processor) AllowsWidth(width int) bool {
+	for _, w := range p.widths {
+		if w == width {
+			return true
+		}
+	}
+	return false
+}
+
+// Widths returns the processor's configured variant widths
+func (p *Processor) Widths() []int {
+	return p.widths
+}
+
+// Variant returns the path of a cached WebP variant of srcPath resized to
+// width pixels, generating it on first use. The cache key includes the
+// source's size and modification time, so edited images are regenerated.
+func (p *Processor) Variant(srcPath string, width int) (string, error) {
+	st, err := os.Stat(srcPath)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d|%d", srcPath, st.Size(), st.ModTime().UnixNano())))
+	cached := filepath.Join(p.cacheDir, hex.EncodeToString(sum[:8])+"-"+strconv.Itoa(width)+variantExt)
+
+	if _, err := os.Stat(cached); err == nil {
+		return cached, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Another request may have generated it while we waited
+	if _, err := os.Stat(cached); err == nil {
+		return cached, nil
+	}
+
+	if err := p.generate(srcPath, cached, width); err != nil {
+		return "", err
+	}
+	return cached, nil
+}
+
+// generate decodes srcPath, scales it to width and writes WebP to dst
+func (p *Processor) generate(srcPath, dst string, width int) error {
+	f, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bounds := src.Bounds()
+	if width > bounds.Dx() {
+		width = bounds.Dx()
+	}
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	scaled := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), src, bounds, draw.Over, nil)
+
+	if err := os.MkdirAll(p.cacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create image cache: %w", err)
+	}
+
+	// Write to a temp file and rename so readers never see a partial variant
+	tmp, err := os.CreateTemp(p.cacheDir, "variant-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := nativewebp.Encode(tmp, scaled, nil); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to encode webp: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), dst)
+}
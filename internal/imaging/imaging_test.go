@@ -0,0 +1,107 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestPNG writes a solid-colour PNG of the given size and returns its path
+func writeTestPNG(t *testing.T, dir string, width, height int) string {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: 40, G: 120, B: 200, A: 255})
+		}
+	}
+
+	path := filepath.Join(dir, "diagram.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create test image: %v", err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	return path
+}
+
+func TestParseVariantPath(t *testing.T) {
+	testCases := []struct {
+		path  string
+		slug  string
+		width int
+		file  string
+		ok    bool
+	}{
+		{"/img/notebook/480/diagram.png.webp", "notebook", 480, "diagram.png", true},
+		{"/img/notebook/960/figures/arch.jpg.webp", "notebook", 960, "figures/arch.jpg", true},
+		{"/img/notebook/480/diagram.png", "", 0, "", false},
+		{"/img/notebook/abc/diagram.png.webp", "", 0, "", false},
+		{"/img/notebook/480/../secret.png.webp", "", 0, "", false},
+		{"/img/notebook/480/notes.md.webp", "", 0, "", false},
+		{"/static/diagram.png.webp", "", 0, "", false},
+	}
+
+	for _, tc := range testCases {
+		slug, width, file, ok := ParseVariantPath(tc.path)
+		if ok != tc.ok || slug != tc.slug || width != tc.width || file != tc.file {
+			t.Errorf("ParseVariantPath(%q) = %q, %d, %q, %v", tc.path, slug, width, file, ok)
+		}
+	}
+
+	if got := VariantURL("notebook", "diagram.png", 480); got != "/img/notebook/480/diagram.png.webp" {
+		t.Errorf("VariantURL round trip mismatch: %s", got)
+	}
+}
+
+func TestVariantWidths(t *testing.T) {
+	widths := VariantWidths(DefaultWidths, 1000)
+	if len(widths) != 2 || widths[0] != 480 || widths[1] != 960 {
+		t.Errorf("Expected [480 960] for a 1000px image, got %v", widths)
+	}
+
+	if widths := VariantWidths(DefaultWidths, 400); len(widths) != 0 {
+		t.Errorf("Expected no variants for a small image, got %v", widths)
+	}
+}
+
+func TestProcessorVariant(t *testing.T) {
+	src := writeTestPNG(t, t.TempDir(), 1000, 500)
+
+	w, h, err := Dimensions(src)
+	if err != nil {
+		t.Fatalf("Dimensions failed: %v", err)
+	}
+	if w != 1000 || h != 500 {
+		t.Errorf("Expected 1000x500, got %dx%d", w, h)
+	}
+
+	p := NewProcessor(t.TempDir(), nil)
+	variant, err := p.Variant(src, 480)
+	if err != nil {
+		t.Fatalf("Variant failed: %v", err)
+	}
+
+	vw, vh, err := Dimensions(variant)
+	if err != nil {
+		t.Fatalf("Failed to read generated variant: %v", err)
+	}
+	if vw != 480 || vh != 240 {
+		t.Errorf("Expected 480x240 variant, got %dx%d", vw, vh)
+	}
+
+	// Second request is served from the cache
+	again, err := p.Variant(src, 480)
+	if err != nil {
+		t.Fatalf("Variant failed on cache hit: %v", err)
+	}
+	if again != variant {
+		t.Errorf("Expected cached path %s, got %s", variant, again)
+	}
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"sort"
+	"time"
+)
+
+// ArchiveMonth is the number of published posts in one calendar month
+type ArchiveMonth struct {
+	Year  int
+	Month time.Month
+	Count int
+}
+
+// ArchiveYear groups a year's months, newest first
+type ArchiveYear struct {
+	Year   int
+	Count  int
+	Months []ArchiveMonth
+}
+
+// GetArchive counts published posts per month, grouped by year, newest first.
+// Months are calendar months in loc, the site's time zone, so a post dated
+// just after midnight locally is not counted in the previous UTC month.
+func (s *Store) GetArchive(loc *time.Location) ([]ArchiveYear, error) {
+	rows, err := s.db.Query("SELECT published_at FROM posts WHERE draft = 0")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type key struct {
+		year  int
+		month time.Month
+	}
+	counts := make(map[key]int)
+	for rows.Next() {
+		var publishedAt string
+		if err := rows.Scan(&publishedAt); err != nil {
+			return nil, err
+		}
+		t, err := time.Parse(time.RFC3339, publishedAt)
+		if err != nil {
+			continue // Unparseable dates cannot be placed in the archive
+		}
+		t = t.In(loc)
+		counts[key{t.Year(), t.Month()}]++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	byYear := make(map[int]*ArchiveYear)
+	for k, n := range counts {
+		y, ok := byYear[k.year]
+		if !ok {
+			y = &ArchiveYear{Year: k.year}
+			byYear[k.year] = y
+		}
+		y.Count += n
+		y.Months = append(y.Months, ArchiveMonth{Year: k.year, Month: k.month, Count: n})
+	}
+
+	years := make([]ArchiveYear, 0, len(byYear))
+	for _, y := range byYear {
+		sort.Slice(y.Months, func(i, j int) bool { return y.Months[i].Month > y.Months[j].Month })
+		years = append(years, *y)
+	}
+	sort.Slice(years, func(i, j int) bool { return years[i].Year > years[j].Year })
+
+	return years, nil
+}
+
+// GetPostsByPeriod returns the published posts in a year, or in one month of
+// it when month is non-zero, newest first. The period is measured in loc.
+func (s *Store) GetPostsByPeriod(year int, month time.Month, loc *time.Location) ([]*Post, error) {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	end := start.AddDate(1, 0, 0)
+	if month != 0 {
+		start = time.Date(year, month, 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 1, 0)
+	}
+
+	// published_at is stored as UTC RFC3339, so string comparison orders correctly
+	query := "SELECT " + postColumns + ` FROM posts p
+		WHERE p.draft = 0 AND p.published_at >= ? AND p.published_at < ?
+		ORDER BY p.published_at DESC`
+
+	rows, err := s.db.Query(query, start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
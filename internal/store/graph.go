@@ -0,0 +1,161 @@
+package store
+
+import (
+	"database/sql"
+
+	"notebook.oceanheart.ai/internal/taxonomy"
+)
+
+// Graph is the notebook's knowledge graph: published posts and the tags they
+// use, connected by post links, tagging and tag namespaces
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// Graph node types
+const (
+	GraphNodePost = "post"
+	GraphNodeTag  = "tag"
+)
+
+// Graph edge types
+const (
+	GraphEdgeLink   = "link"   // Post to a post it links to
+	GraphEdgeTag    = "tag"    // Post to one of its tags
+	GraphEdgeParent = "parent" // Namespace parent tag to child tag
+)
+
+// GraphNode is a post or a tag. IDs are prefixed with the type ("post:slug",
+// "tag:name") so a post and a tag with the same name stay distinct.
+type GraphNode struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Label    string `json:"label"`
+	URL      string `json:"url"`
+	Category string `json:"category,omitempty"` // Taxonomy category of a tag
+}
+
+// GraphEdge connects two node IDs
+type GraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+func postNodeID(slug string) string { return GraphNodePost + ":" + slug }
+func tagNodeID(name string) string  { return GraphNodeTag + ":" + name }
+
+// publishedPosts selects the posts shown on the public site
+const publishedPosts = "SELECT id FROM posts WHERE draft = 0 AND published_at <= datetime('now')"
+
+// GetGraph returns the graph of published posts, newest first, and the tags
+// they use along with those tags' namespace parents. Links to drafts, future
+// posts and missing slugs are left out, as are links from a post to itself.
+func (s *Store) GetGraph() (*Graph, error) {
+	graph := &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+
+	rows, err := s.db.Query(`
+		SELECT slug, title FROM posts
+		WHERE id IN (` + publishedPosts + `)
+		ORDER BY published_at DESC, slug ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	err = scanGraph(rows, func(slug, title string) {
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			ID: postNodeID(slug), Type: GraphNodePost, Label: title, URL: "/p/" + slug,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(`
+		WITH RECURSIVE in_use(id) AS (
+			SELECT tag_id FROM post_tags WHERE post_id IN (` + publishedPosts + `)
+			UNION
+			SELECT t.parent_id FROM tags t JOIN in_use ON t.id = in_use.id
+			WHERE t.parent_id IS NOT NULL
+		)
+		SELECT name, display_name FROM tags
+		WHERE id IN (SELECT id FROM in_use)
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	tax := s.Taxonomy()
+	err = scanGraph(rows, func(name, display string) {
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			ID: tagNodeID(name), Type: GraphNodeTag, Label: display,
+			URL: taxonomy.TagURL(name), Category: tax.Categorize(name),
+		})
+		if parent := taxonomy.Parent(name); parent != "" {
+			graph.Edges = append(graph.Edges, GraphEdge{
+				Source: tagNodeID(parent), Target: tagNodeID(name), Type: GraphEdgeParent,
+			})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(`
+		SELECT p.slug, l.target_slug
+		FROM post_links l
+		JOIN posts p ON p.id = l.source_id
+		JOIN posts target ON target.slug = l.target_slug
+		WHERE p.id IN (` + publishedPosts + `)
+		  AND target.id IN (` + publishedPosts + `)
+		  AND p.id != target.id
+		ORDER BY p.slug, l.target_slug
+	`)
+	if err != nil {
+		return nil, err
+	}
+	err = scanGraph(rows, func(source, target string) {
+		graph.Edges = append(graph.Edges, GraphEdge{
+			Source: postNodeID(source), Target: postNodeID(target), Type: GraphEdgeLink,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(`
+		SELECT p.slug, t.name
+		FROM post_tags pt
+		JOIN posts p ON p.id = pt.post_id
+		JOIN tags t ON t.id = pt.tag_id
+		WHERE p.id IN (` + publishedPosts + `)
+		ORDER BY p.slug, t.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	err = scanGraph(rows, func(slug, name string) {
+		graph.Edges = append(graph.Edges, GraphEdge{
+			Source: postNodeID(slug), Target: tagNodeID(name), Type: GraphEdgeTag,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return graph, nil
+}
+
+// scanGraph calls fn with each row of two string columns and closes rows
+func scanGraph(rows *sql.Rows, fn func(a, b string)) error {
+	defer rows.Close()
+	for rows.Next() {
+		var a, b string
+		if err := rows.Scan(&a, &b); err != nil {
+			return err
+		}
+		fn(a, b)
+	}
+	return rows.Err()
+}
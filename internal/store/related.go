@@ -0,0 +1,216 @@
+package store
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Related post scoring. Each shared tag contributes its inverse document
+// frequency, so two posts sharing a rare tag rank above two posts sharing a
+// tag that is on everything. Term overlap between titles and summaries adds a
+// smaller amount, which also surfaces related posts that share no tags.
+const (
+	relatedTextWeight = 2.0 // Weight of the term overlap (0..1) relative to one tag
+	relatedMinScore   = 0.2 // Below this a post is not considered related
+)
+
+// GetRelatedPosts returns up to n published posts related to postID, most
+// related first. Ties go to the newer post.
+func (s *Store) GetRelatedPosts(postID int, n int) ([]*Post, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	candidates, err := s.relatedCandidates()
+	if err != nil {
+		return nil, err
+	}
+
+	source, ok := candidates[postID]
+	if !ok {
+		// Drafts and future posts still get suggestions; read the source directly
+		source = &relatedCandidate{id: postID}
+		err := s.db.QueryRow("SELECT title, summary, excerpt FROM posts WHERE id = ?", postID).
+			Scan(&source.title, &source.summary, &source.excerpt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	delete(candidates, postID)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	scores, err := s.sharedTagScores(postID, len(candidates)+1)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceTerms := source.terms()
+	type scored struct {
+		id          int
+		score       float64
+		publishedAt string
+	}
+	var ranked []scored
+	for id, c := range candidates {
+		score := scores[id] + relatedTextWeight*termOverlap(sourceTerms, c.terms())
+		if score < relatedMinScore {
+			continue
+		}
+		ranked = append(ranked, scored{id: id, score: score, publishedAt: c.publishedAt})
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].publishedAt > ranked[j].publishedAt
+	})
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+
+	posts := make([]*Post, 0, len(ranked))
+	for _, r := range ranked {
+		post, err := scanPost(s.db.QueryRow("SELECT "+postColumns+" FROM posts p WHERE p.id = ?", r.id))
+		if err != nil {
+			return nil, err
+		}
+		if err := s.loadPostTags(post); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, nil
+}
+
+// relatedCandidate is the text of a published post used for term overlap
+type relatedCandidate struct {
+	id          int
+	title       string
+	summary     string
+	excerpt     string
+	publishedAt string
+}
+
+// terms returns the distinct significant words of the title and description
+func (c *relatedCandidate) terms() map[string]bool {
+	text := c.title + " " + c.summary
+	if c.summary == "" {
+		text = c.title + " " + c.excerpt
+	}
+	return significantTerms(text)
+}
+
+// relatedCandidates loads every published post keyed by ID
+func (s *Store) relatedCandidates() (map[int]*relatedCandidate, error) {
+	rows, err := s.db.Query(`
+		SELECT id, title, summary, excerpt, published_at
+		FROM posts
+		WHERE draft = 0
+		  AND published_at <= datetime('now')
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := make(map[int]*relatedCandidate)
+	for rows.Next() {
+		var c relatedCandidate
+		if err := rows.Scan(&c.id, &c.title, &c.summary, &c.excerpt, &c.publishedAt); err != nil {
+			return nil, err
+		}
+		candidates[c.id] = &c
+	}
+
+	return candidates, rows.Err()
+}
+
+// sharedTagScores sums, for every other post, the IDF weight of each tag it
+// shares with postID. total is the number of posts tag frequencies are
+// measured against.
+func (s *Store) sharedTagScores(postID int, total int) (map[int]float64, error) {
+	rows, err := s.db.Query(`
+		SELECT other.post_id,
+		       (SELECT COUNT(*) FROM post_tags df WHERE df.tag_id = mine.tag_id)
+		FROM post_tags mine
+		JOIN post_tags other ON other.tag_id = mine.tag_id AND other.post_id != mine.post_id
+		WHERE mine.post_id = ?
+	`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scores := make(map[int]float64)
+	for rows.Next() {
+		var id, df int
+		if err := rows.Scan(&id, &df); err != nil {
+			return nil, err
+		}
+		scores[id] += tagWeight(df, total)
+	}
+
+	return scores, rows.Err()
+}
+
+// tagWeight is the inverse document frequency of a tag used by df of total
+// posts, offset so that even a tag on every post counts for something
+func tagWeight(df, total int) float64 {
+	if df < 1 {
+		df = 1
+	}
+	if total < df {
+		total = df
+	}
+	return 1 + math.Log(float64(total)/float64(df))
+}
+
+// termOverlap is the Jaccard similarity of two term sets
+func termOverlap(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// stopWords are common words that say nothing about a post's topic
+var stopWords = map[string]bool{
+	"about": true, "after": true, "also": true, "and": true, "are": true,
+	"because": true, "been": true, "but": true, "can": true, "for": true,
+	"from": true, "has": true, "have": true, "how": true, "into": true,
+	"its": true, "just": true, "more": true, "not": true, "now": true,
+	"one": true, "our": true, "out": true, "over": true, "than": true,
+	"that": true, "the": true, "their": true, "them": true, "then": true,
+	"there": true, "these": true, "they": true, "this": true, "through": true,
+	"using": true, "was": true, "what": true, "when": true, "which": true,
+	"while": true, "who": true, "why": true, "will": true, "with": true,
+	"you": true, "your": true,
+}
+
+// significantTerms lowercases text and returns its words of three or more
+// letters that are not stop words
+func significantTerms(text string) map[string]bool {
+	terms := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if len([]rune(w)) < 3 || stopWords[w] {
+			continue
+		}
+		terms[w] = true
+	}
+	return terms
+}
@@ -2,15 +2,24 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"math"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 
 	_ "github.com/mattn/go-sqlite3"
 	_ "github.com/tursodatabase/libsql-client-go/libsql"
+	"notebook.oceanheart.ai/internal/taxonomy"
 )
 
 type Store struct {
 	db *sql.DB
+
+	mu       sync.RWMutex
+	taxonomy *taxonomy.Taxonomy // Tag categories, replaced on content reload
 }
 
 type Post struct {
@@ -23,12 +32,61 @@ type Post struct {
 	PublishedAt string
 	UpdatedAt   string
 	Draft       bool
-	Tags        []string // Tags associated with the post
+	Section     string            // Top-level content directory; empty for root posts
+	BundleDir   string            // Page bundle directory relative to the content root; empty for single-file posts
+	TOC         []Heading         // Heading tree for the table of contents
+	ShowTOC     bool              // Render the table of contents (toc: front matter)
+	WordCount   int               // Prose words, computed at load time
+	ReadingTime int               // Estimated minutes to read
+	Excerpt     string            // First paragraph as plain text, used when Summary is empty
+	Series      string            // Multi-part series name (series: front matter)
+	SeriesOrder int               // Position within the series (series_order: front matter)
+	Tags        []string          // Normalised tags associated with the post
+	TagNames    map[string]string // Display name by tag, as first written
+	Links       []string          // Slugs of the posts this post links to, from its /p/ hrefs
+	Math        bool              // Load the math assets (math: front matter)
+	Mermaid     bool              // Load mermaid.js; set when a diagram is left to it at render time
+	Code        bool              // Has highlighted code blocks, which get copy buttons; set at render time
+}
+
+// TagName returns the display name of one of the post's tags
+func (p *Post) TagName(tag string) string {
+	if name := p.TagNames[tag]; name != "" {
+		return name
+	}
+	return tag
+}
+
+// Description returns the post's summary, falling back to its excerpt
+func (p *Post) Description() string {
+	if p.Summary != "" {
+		return p.Summary
+	}
+	return p.Excerpt
+}
+
+// Heading is a table of contents entry linking to a heading anchor
+type Heading struct {
+	Level    int       `json:"level"`
+	ID       string    `json:"id"`
+	Text     string    `json:"text"`
+	Children []Heading `json:"children,omitempty"`
+}
+
+// Section is a top-level content directory with its own index page
+type Section struct {
+	Name      string // Directory name, also the URL prefix (/{name}/)
+	Title     string
+	Summary   string
+	IntroHTML string // Rendered body of the section's _index.md
+	PostCount int
 }
 
 type Tag struct {
-	ID   int
-	Name string
+	ID          int
+	Name        string // Normalised form used for matching and URLs
+	DisplayName string // Form shown on pages, as first written
+	ParentID    int    // 0 for a top-level tag
 }
 
 // MustOpen opens a database (SQLite or Turso) based on environment variables
@@ -60,7 +118,7 @@ func MustOpen(dbPath string) *Store {
 		panic(fmt.Sprintf("failed to connect to database: %v", err))
 	}
 
-	store := &Store{db: db}
+	store := &Store{db: db, taxonomy: taxonomy.Default()}
 	if err := store.migrate(); err != nil {
 		panic(fmt.Sprintf("failed to migrate database: %v", err))
 	}
@@ -95,14 +153,8 @@ func (s *Store) migrate() error {
 			continue
 		}
 
-		// Execute migration
-		if _, err := s.db.Exec(migration.SQL); err != nil {
-			return fmt.Errorf("failed to execute migration %s: %w", migration.Version, err)
-		}
-
-		// Record migration
-		if _, err := s.db.Exec("INSERT INTO schema_migrations (version) VALUES (?)", migration.Version); err != nil {
-			return fmt.Errorf("failed to record migration %s: %w", migration.Version, err)
+		if err := s.applyMigration(migration); err != nil {
+			return err
 		}
 	}
 
@@ -112,6 +164,103 @@ func (s *Store) migrate() error {
 type Migration struct {
 	Version string
 	SQL     string
+	Run     func(tx *sql.Tx) error // Optional data migration run after SQL
+}
+
+// applyMigration executes a migration's SQL, runs its data migration and
+// records its version in one transaction, so a failure leaves neither the
+// schema change nor the record behind and the migration is retried whole
+func (s *Store) applyMigration(migration Migration) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", migration.Version, err)
+	}
+	defer tx.Rollback()
+
+	if migration.SQL != "" {
+		if _, err := tx.Exec(migration.SQL); err != nil {
+			return fmt.Errorf("failed to execute migration %s: %w", migration.Version, err)
+		}
+	}
+	if migration.Run != nil {
+		if err := migration.Run(tx); err != nil {
+			return fmt.Errorf("failed to execute migration %s: %w", migration.Version, err)
+		}
+	}
+
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version) VALUES (?)", migration.Version); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", migration.Version, err)
+	}
+	return tx.Commit()
+}
+
+// mergeDuplicateTags renames every tag to its normalised form, folding tags
+// that normalise alike ("Go", "go", " go") into the oldest one and moving
+// their posts and child tags across. Tags that normalise to nothing are
+// dropped. Namespace parents are relinked by ensureTag on the next load.
+func mergeDuplicateTags(tx *sql.Tx) error {
+	rows, err := tx.Query("SELECT id, name FROM tags ORDER BY id")
+	if err != nil {
+		return err
+	}
+	type tagRow struct {
+		id      int
+		name    string
+		display string
+	}
+	var all []tagRow
+	for rows.Next() {
+		var t tagRow
+		if err := rows.Scan(&t.id, &t.name); err != nil {
+			rows.Close()
+			return err
+		}
+		all = append(all, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	keep := make(map[string]int) // Normalised name -> surviving tag ID
+	var renames []tagRow
+	for _, t := range all {
+		key := taxonomy.Normalize(t.name)
+		target, seen := keep[key]
+		if !seen && key != "" {
+			keep[key] = t.id
+			if key != t.name {
+				renames = append(renames, tagRow{t.id, key, taxonomy.DisplayName(t.name)})
+			}
+			continue
+		}
+
+		// Duplicate (or empty) tag: move its links to the survivor, then drop it
+		if key != "" {
+			if _, err := tx.Exec("INSERT OR IGNORE INTO post_tags (post_id, tag_id) SELECT post_id, ? FROM post_tags WHERE tag_id = ?", target, t.id); err != nil {
+				return err
+			}
+			if _, err := tx.Exec("UPDATE tags SET parent_id = ? WHERE parent_id = ?", target, t.id); err != nil {
+				return err
+			}
+		} else if _, err := tx.Exec("UPDATE tags SET parent_id = NULL WHERE parent_id = ?", t.id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM post_tags WHERE tag_id = ?", t.id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM tags WHERE id = ?", t.id); err != nil {
+			return err
+		}
+	}
+
+	// Rename once the duplicates holding those names are gone
+	for _, r := range renames {
+		if _, err := tx.Exec("UPDATE tags SET name = ?, display_name = ? WHERE id = ?", r.name, r.display, r.id); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *Store) loadMigrations() ([]Migration, error) {
@@ -153,6 +302,102 @@ CREATE INDEX idx_posts_published ON posts(published_at DESC, draft);
 CREATE INDEX idx_tags_name ON tags(name);`,
 	})
 
+	migrations = append(migrations, Migration{
+		Version: "002_sections",
+		SQL: `-- Content sections mapped from top-level content directories
+ALTER TABLE posts ADD COLUMN section TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE sections (
+  name TEXT PRIMARY KEY,
+  title TEXT NOT NULL,
+  summary TEXT NOT NULL DEFAULT '',
+  intro_html TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_posts_section ON posts(section, published_at DESC);`,
+	})
+
+	migrations = append(migrations, Migration{
+		Version: "003_bundles",
+		SQL: `-- Page bundles: posts stored as a directory with co-located assets
+ALTER TABLE posts ADD COLUMN bundle_dir TEXT NOT NULL DEFAULT '';`,
+	})
+
+	migrations = append(migrations, Migration{
+		Version: "004_toc",
+		SQL: `-- Table of contents: heading tree as JSON plus the toc: front matter flag
+ALTER TABLE posts ADD COLUMN toc TEXT NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN show_toc BOOLEAN NOT NULL DEFAULT 0;`,
+	})
+
+	migrations = append(migrations, Migration{
+		Version: "005_post_stats",
+		SQL: `-- Word count, reading time and auto-excerpt computed at load time
+ALTER TABLE posts ADD COLUMN word_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN reading_time INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN excerpt TEXT NOT NULL DEFAULT '';`,
+	})
+
+	migrations = append(migrations, Migration{
+		Version: "006_series",
+		SQL: `-- Multi-part series from series: and series_order: front matter
+ALTER TABLE posts ADD COLUMN series TEXT NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN series_order INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_posts_series ON posts(series, series_order);`,
+	})
+
+	migrations = append(migrations, Migration{
+		Version: "007_tag_hierarchy",
+		SQL: `-- Namespaced tags: "a:b" is the child of "a"; parents are created on upsert
+ALTER TABLE tags ADD COLUMN parent_id INTEGER REFERENCES tags(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tags_parent ON tags(parent_id);`,
+	})
+
+	migrations = append(migrations, Migration{
+		Version: "008_tag_display_names",
+		SQL: `-- Tags are stored normalised (name) with the form they were written in
+-- (display_name); mergeDuplicateTags then normalises existing names
+ALTER TABLE tags ADD COLUMN display_name TEXT NOT NULL DEFAULT '';
+
+UPDATE tags SET display_name = name;`,
+		Run: mergeDuplicateTags,
+	})
+
+	migrations = append(migrations, Migration{
+		Version: "009_post_links",
+		SQL: `-- Links between posts by target slug, so a link can point at a post that
+-- is loaded later in the same batch; backlinks are read by target_slug
+CREATE TABLE post_links (
+  source_id   INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+  target_slug TEXT NOT NULL,
+  PRIMARY KEY (source_id, target_slug)
+);
+
+CREATE INDEX idx_post_links_target ON post_links(target_slug);`,
+	})
+
+	migrations = append(migrations, Migration{
+		Version: "010_math",
+		SQL: `-- math: front matter; pages load the math assets only for these posts
+ALTER TABLE posts ADD COLUMN math BOOLEAN NOT NULL DEFAULT 0;`,
+	})
+
+	migrations = append(migrations, Migration{
+		Version: "011_mermaid",
+		SQL: `-- Set at render time for posts with a diagram drawn by mermaid.js, so
+-- pages load it only for these posts
+ALTER TABLE posts ADD COLUMN mermaid BOOLEAN NOT NULL DEFAULT 0;`,
+	})
+
+	migrations = append(migrations, Migration{
+		Version: "012_code",
+		SQL: `-- Set at render time for posts with highlighted code blocks, so pages
+-- load the copy buttons only for these posts
+ALTER TABLE posts ADD COLUMN code BOOLEAN NOT NULL DEFAULT 0;`,
+	})
+
 	return migrations, nil
 }
 
@@ -165,6 +410,20 @@ func (s *Store) isMigrationApplied(version string) (bool, error) {
 	return count > 0, nil
 }
 
+// SetTaxonomy replaces the taxonomy used to categorise tags
+func (s *Store) SetTaxonomy(t *taxonomy.Taxonomy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.taxonomy = t
+}
+
+// Taxonomy returns the taxonomy used to categorise tags
+func (s *Store) Taxonomy() *taxonomy.Taxonomy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.taxonomy
+}
+
 // Close closes the database connection
 func (s *Store) Close() error {
 	if s.db != nil {
@@ -173,16 +432,52 @@ func (s *Store) Close() error {
 	return nil
 }
 
+// postColumns lists the posts columns read by every post query, aliased as p
+const postColumns = "p.id, p.slug, p.title, p.summary, p.html, p.raw_md, p.published_at, p.updated_at, p.draft, p.section, p.bundle_dir, p.toc, p.show_toc, p.word_count, p.reading_time, p.excerpt, p.series, p.series_order, p.math, p.mermaid, p.code"
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPost reads a row selected with postColumns
+func scanPost(row rowScanner) (*Post, error) {
+	var p Post
+	var toc string
+	err := row.Scan(&p.ID, &p.Slug, &p.Title, &p.Summary, &p.HTML, &p.RawMD, &p.PublishedAt, &p.UpdatedAt, &p.Draft, &p.Section, &p.BundleDir, &toc, &p.ShowTOC, &p.WordCount, &p.ReadingTime, &p.Excerpt, &p.Series, &p.SeriesOrder, &p.Math, &p.Mermaid, &p.Code)
+	if err != nil {
+		return nil, err
+	}
+	if toc != "" {
+		if err := json.Unmarshal([]byte(toc), &p.TOC); err != nil {
+			return nil, fmt.Errorf("failed to decode toc for %s: %w", p.Slug, err)
+		}
+	}
+	return &p, nil
+}
+
+// encodeTOC serialises a heading tree for the toc column
+func encodeTOC(toc []Heading) (string, error) {
+	if len(toc) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(toc)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
 // Basic CRUD operations for posts
 func (s *Store) GetAllPosts(includeDrafts bool) ([]Post, error) {
-	query := "SELECT id, slug, title, summary, html, raw_md, published_at, updated_at, draft FROM posts"
+	query := "SELECT " + postColumns + " FROM posts p"
 	args := []interface{}{}
 	
 	if !includeDrafts {
-		query += " WHERE draft = 0"
+		query += " WHERE p.draft = 0"
 	}
 	
-	query += " ORDER BY published_at DESC"
+	query += " ORDER BY p.published_at DESC"
 	
 	rows, err := s.db.Query(query, args...)
 	if err != nil {
@@ -192,22 +487,61 @@ func (s *Store) GetAllPosts(includeDrafts bool) ([]Post, error) {
 
 	var posts []Post
 	for rows.Next() {
-		var p Post
-		err := rows.Scan(&p.ID, &p.Slug, &p.Title, &p.Summary, &p.HTML, &p.RawMD, &p.PublishedAt, &p.UpdatedAt, &p.Draft)
+		p, err := scanPost(rows)
 		if err != nil {
 			return nil, err
 		}
-		posts = append(posts, p)
+		posts = append(posts, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return posts, rows.Err()
+	tagsByPost, names, err := s.getAllPostTags()
+	if err != nil {
+		return nil, err
+	}
+	for i := range posts {
+		posts[i].Tags = tagsByPost[posts[i].ID]
+		posts[i].TagNames = names
+	}
+
+	return posts, nil
+}
+
+// getAllPostTags retrieves the tags of every post in one query, keyed by post
+// ID, with the display names of those tags
+func (s *Store) getAllPostTags() (map[int][]string, map[string]string, error) {
+	rows, err := s.db.Query(`
+		SELECT pt.post_id, t.name, t.display_name
+		FROM post_tags pt
+		JOIN tags t ON t.id = pt.tag_id
+		ORDER BY pt.post_id, t.name
+	`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[int][]string)
+	names := make(map[string]string)
+	for rows.Next() {
+		var postID int
+		var name, display string
+		if err := rows.Scan(&postID, &name, &display); err != nil {
+			return nil, nil, err
+		}
+		tags[postID] = append(tags[postID], name)
+		names[name] = display
+	}
+
+	return tags, names, rows.Err()
 }
 
 func (s *Store) GetPostBySlug(slug string) (*Post, error) {
-	query := "SELECT id, slug, title, summary, html, raw_md, published_at, updated_at, draft FROM posts WHERE slug = ?"
+	query := "SELECT " + postColumns + " FROM posts p WHERE p.slug = ?"
 	
-	var p Post
-	err := s.db.QueryRow(query, slug).Scan(&p.ID, &p.Slug, &p.Title, &p.Summary, &p.HTML, &p.RawMD, &p.PublishedAt, &p.UpdatedAt, &p.Draft)
+	p, err := scanPost(s.db.QueryRow(query, slug))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -215,13 +549,17 @@ func (s *Store) GetPostBySlug(slug string) (*Post, error) {
 		return nil, err
 	}
 
-	return &p, nil
+	if err := s.loadPostTags(p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
 }
 
 func (s *Store) UpsertPost(p *Post) error {
 	query := `
-		INSERT INTO posts (slug, title, summary, html, raw_md, published_at, updated_at, draft)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO posts (slug, title, summary, html, raw_md, published_at, updated_at, draft, section, bundle_dir, toc, show_toc, word_count, reading_time, excerpt, series, series_order, math, mermaid, code)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(slug) DO UPDATE SET
 			title = excluded.title,
 			summary = excluded.summary,
@@ -229,10 +567,27 @@ func (s *Store) UpsertPost(p *Post) error {
 			raw_md = excluded.raw_md,
 			published_at = excluded.published_at,
 			updated_at = excluded.updated_at,
-			draft = excluded.draft
+			draft = excluded.draft,
+			section = excluded.section,
+			bundle_dir = excluded.bundle_dir,
+			toc = excluded.toc,
+			show_toc = excluded.show_toc,
+			word_count = excluded.word_count,
+			reading_time = excluded.reading_time,
+			excerpt = excluded.excerpt,
+			series = excluded.series,
+			series_order = excluded.series_order,
+			math = excluded.math,
+			mermaid = excluded.mermaid,
+			code = excluded.code
 	`
 	
-	_, err := s.db.Exec(query, p.Slug, p.Title, p.Summary, p.HTML, p.RawMD, p.PublishedAt, p.UpdatedAt, p.Draft)
+	toc, err := encodeTOC(p.TOC)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(query, p.Slug, p.Title, p.Summary, p.HTML, p.RawMD, p.PublishedAt, p.UpdatedAt, p.Draft, p.Section, p.BundleDir, toc, p.ShowTOC, p.WordCount, p.ReadingTime, p.Excerpt, p.Series, p.SeriesOrder, p.Math, p.Mermaid, p.Code)
 	return err
 }
 
@@ -246,8 +601,8 @@ func (s *Store) UpsertPosts(posts []*Post) error {
 
 	// Prepare statement for post upserts
 	postStmt, err := tx.Prepare(`
-		INSERT INTO posts (slug, title, summary, html, raw_md, published_at, updated_at, draft)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO posts (slug, title, summary, html, raw_md, published_at, updated_at, draft, section, bundle_dir, toc, show_toc, word_count, reading_time, excerpt, series, series_order, math, mermaid, code)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(slug) DO UPDATE SET
 			title = excluded.title,
 			summary = excluded.summary,
@@ -255,7 +610,19 @@ func (s *Store) UpsertPosts(posts []*Post) error {
 			raw_md = excluded.raw_md,
 			published_at = excluded.published_at,
 			updated_at = excluded.updated_at,
-			draft = excluded.draft
+			draft = excluded.draft,
+			section = excluded.section,
+			bundle_dir = excluded.bundle_dir,
+			toc = excluded.toc,
+			show_toc = excluded.show_toc,
+			word_count = excluded.word_count,
+			reading_time = excluded.reading_time,
+			excerpt = excluded.excerpt,
+			series = excluded.series,
+			series_order = excluded.series_order,
+			math = excluded.math,
+			mermaid = excluded.mermaid,
+			code = excluded.code
 	`)
 	if err != nil {
 		return err
@@ -263,133 +630,202 @@ func (s *Store) UpsertPosts(posts []*Post) error {
 	defer postStmt.Close()
 
 	for _, post := range posts {
-		result, err := postStmt.Exec(post.Slug, post.Title, post.Summary, post.HTML, post.RawMD, post.PublishedAt, post.UpdatedAt, post.Draft)
+		toc, err := encodeTOC(post.TOC)
+		if err != nil {
+			return err
+		}
+
+		_, err = postStmt.Exec(post.Slug, post.Title, post.Summary, post.HTML, post.RawMD, post.PublishedAt, post.UpdatedAt, post.Draft, post.Section, post.BundleDir, toc, post.ShowTOC, post.WordCount, post.ReadingTime, post.Excerpt, post.Series, post.SeriesOrder, post.Math, post.Mermaid, post.Code)
+		if err != nil {
+			return err
+		}
+
+		// Get the post ID. LastInsertId is stale when the upsert updated an
+		// existing row, so look the post up by slug instead.
+		var postID int64
+		err = tx.QueryRow("SELECT id FROM posts WHERE slug = ?", post.Slug).Scan(&postID)
 		if err != nil {
 			return err
 		}
 
-		// Get the post ID
-		postID, err := result.LastInsertId()
+		// Replace the post's tag set, so tags removed from the front matter
+		// (including all of them) are unlinked
+		_, err = tx.Exec("DELETE FROM post_tags WHERE post_id = ?", postID)
 		if err != nil {
-			// Try to get the ID by slug for existing posts
-			err = tx.QueryRow("SELECT id FROM posts WHERE slug = ?", post.Slug).Scan(&postID)
+			return err
+		}
+
+		for _, tagName := range post.Tags {
+			// Get or create tag, along with its namespace parents
+			tagID, err := ensureTag(tx, tagName, post.TagNames[tagName])
 			if err != nil {
 				return err
 			}
-		}
+			if tagID == 0 {
+				continue // Nothing left after normalisation
+			}
 
-		// Handle tags if present
-		if len(post.Tags) > 0 {
-			// Clear existing tags for this post
-			_, err = tx.Exec("DELETE FROM post_tags WHERE post_id = ?", postID)
+			// Link post to tag; two spellings of one tag link once
+			_, err = tx.Exec("INSERT OR IGNORE INTO post_tags (post_id, tag_id) VALUES (?, ?)", postID, tagID)
 			if err != nil {
 				return err
 			}
+		}
 
-			// Add new tags
-			for _, tagName := range post.Tags {
-				// Get or create tag
-				var tagID int
-				err = tx.QueryRow("SELECT id FROM tags WHERE name = ?", tagName).Scan(&tagID)
-				if err != nil {
-					// Create new tag
-					result, err := tx.Exec("INSERT INTO tags (name) VALUES (?)", tagName)
-					if err != nil {
-						return err
-					}
-					id, err := result.LastInsertId()
-					if err != nil {
-						return err
-					}
-					tagID = int(id)
-				}
-
-				// Link post to tag
-				_, err = tx.Exec("INSERT INTO post_tags (post_id, tag_id) VALUES (?, ?)", postID, tagID)
-				if err != nil {
-					return err
-				}
+		// Replace the post's outgoing links the same way
+		_, err = tx.Exec("DELETE FROM post_links WHERE source_id = ?", postID)
+		if err != nil {
+			return err
+		}
+		for _, target := range post.Links {
+			_, err = tx.Exec("INSERT OR IGNORE INTO post_links (source_id, target_slug) VALUES (?, ?)", postID, target)
+			if err != nil {
+				return err
 			}
 		}
 	}
 
+	if err := deleteUnusedTags(tx); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
-// GetOrCreateTag gets existing tag or creates new one
-func (s *Store) GetOrCreateTag(name string) (*Tag, error) {
-	// Try to get existing tag
-	var tag Tag
-	err := s.db.QueryRow("SELECT id, name FROM tags WHERE name = ?", name).Scan(&tag.ID, &tag.Name)
-	if err == nil {
-		return &tag, nil
-	}
+// deleteUnusedTags removes tags no post uses, keeping the namespace parents
+// of tags that are still in use
+func deleteUnusedTags(db execQueryer) error {
+	_, err := db.Exec(`
+		WITH RECURSIVE in_use(id) AS (
+			SELECT DISTINCT tag_id FROM post_tags
+			UNION
+			SELECT t.parent_id FROM tags t JOIN in_use ON t.id = in_use.id
+			WHERE t.parent_id IS NOT NULL
+		)
+		DELETE FROM tags WHERE id NOT IN (SELECT id FROM in_use)
+	`)
+	return err
+}
 
-	// Create new tag
-	result, err := s.db.Exec("INSERT INTO tags (name) VALUES (?)", name)
+// GetOrCreateTag gets existing tag or creates new one, creating its
+// namespace parents as needed. name is normalised; as written, it becomes the
+// display name of a new tag.
+func (s *Store) GetOrCreateTag(name string) (*Tag, error) {
+	id, err := ensureTag(s.db, name, "")
 	if err != nil {
 		return nil, err
 	}
+	if id == 0 {
+		return nil, fmt.Errorf("tag %q is empty after normalisation", name)
+	}
+	return s.getTag("id = ?", id)
+}
+
+// GetTag returns the tag with the given name in any spelling, or nil
+func (s *Store) GetTag(name string) (*Tag, error) {
+	tag, err := s.getTag("name = ?", taxonomy.Normalize(name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return tag, err
+}
 
-	id, err := result.LastInsertId()
+// getTag loads the tag matching a WHERE condition
+func (s *Store) getTag(where string, arg interface{}) (*Tag, error) {
+	var tag Tag
+	var parentID sql.NullInt64
+	err := s.db.QueryRow("SELECT id, name, display_name, parent_id FROM tags WHERE "+where, arg).
+		Scan(&tag.ID, &tag.Name, &tag.DisplayName, &parentID)
 	if err != nil {
 		return nil, err
 	}
+	tag.ParentID = int(parentID.Int64)
+	return &tag, nil
+}
 
-	return &Tag{ID: int(id), Name: name}, nil
+// execQueryer is satisfied by both *sql.DB and *sql.Tx
+type execQueryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
 }
 
-// LinkPostTags links a post to tags
-func (s *Store) LinkPostTags(postID int, tagNames []string) error {
-	if len(tagNames) == 0 {
-		return nil
+// ensureTag returns the ID of the tag name normalises to, creating it and any
+// missing namespace ancestors ("a" and "a:b" for "a:b:c") and linking each to
+// its parent. New tags are displayed as display, or as name when display does
+// not normalise to the same tag. An empty tag returns 0.
+func ensureTag(db execQueryer, name, display string) (int, error) {
+	key := taxonomy.Normalize(name)
+	if key == "" {
+		return 0, nil
 	}
-
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
+	if display == "" || taxonomy.Normalize(display) != key {
+		display = taxonomy.DisplayName(name)
 	}
-	defer tx.Rollback()
-
-	// Clear existing tags for this post
-	_, err = tx.Exec("DELETE FROM post_tags WHERE post_id = ?", postID)
-	if err != nil {
-		return err
+	keys := taxonomy.Ancestors(key)
+	labels := taxonomy.Ancestors(display)
+	if len(labels) != len(keys) {
+		labels = keys // Levels dropped by normalisation; fall back to the key
 	}
 
-	// Add new tags
-	for _, tagName := range tagNames {
-		tag, err := s.GetOrCreateTag(tagName)
-		if err != nil {
-			return err
-		}
-
-		_, err = tx.Exec("INSERT INTO post_tags (post_id, tag_id) VALUES (?, ?)", postID, tag.ID)
-		if err != nil {
-			return err
+	var parentID sql.NullInt64
+	var id int
+	for i, level := range keys {
+		var current sql.NullInt64
+		err := db.QueryRow("SELECT id, parent_id FROM tags WHERE name = ?", level).Scan(&id, &current)
+		switch {
+		case err == sql.ErrNoRows:
+			result, err := db.Exec("INSERT INTO tags (name, display_name, parent_id) VALUES (?, ?, ?)", level, labels[i], parentID)
+			if err != nil {
+				return 0, err
+			}
+			newID, err := result.LastInsertId()
+			if err != nil {
+				return 0, err
+			}
+			id = int(newID)
+		case err != nil:
+			return 0, err
+		case current != parentID:
+			// Tags created before namespaces were modelled have no parent yet
+			if _, err := db.Exec("UPDATE tags SET parent_id = ? WHERE id = ?", parentID, id); err != nil {
+				return 0, err
+			}
 		}
+		parentID = sql.NullInt64{Int64: int64(id), Valid: true}
 	}
-
-	return tx.Commit()
+	return id, nil
 }
 
 // PopularTag represents a tag with its usage count
 type PopularTag struct {
-	Name     string
-	Count    int
-	Category string // "cognitive", "bias", "technical", "general"
+	Name       string // Normalised tag
+	Display    string // Display name
+	Count      int
+	Category   string // Taxonomy category name, e.g. "cognitive" or "general"
+	Color      string // Category colours from the taxonomy; empty for the default style
+	Background string
+	Weight     int // Tag cloud size from 1 (least used) to tagCloudLevels
 }
 
-// GetPopularTags returns the most popular tags by post count
+// tagCloudLevels is the number of tag cloud sizes
+const tagCloudLevels = 5
+
+// GetPopularTags returns the most popular tags by post count, ordered by
+// taxonomy category and then by count. A limit of zero or less returns every
+// tag in use.
 func (s *Store) GetPopularTags(limit int) ([]PopularTag, error) {
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+
 	query := `
-		SELECT t.name, COUNT(pt.post_id) as post_count
+		SELECT t.name, t.display_name, COUNT(pt.post_id) as post_count
 		FROM tags t
 		JOIN post_tags pt ON t.id = pt.tag_id
 		JOIN posts p ON pt.post_id = p.id
 		WHERE p.draft = 0 
 		  AND p.published_at <= datetime('now')
-		GROUP BY t.name
+		GROUP BY t.id
 		ORDER BY post_count DESC, t.name ASC
 		LIMIT ?
 	`
@@ -400,90 +836,112 @@ func (s *Store) GetPopularTags(limit int) ([]PopularTag, error) {
 	}
 	defer rows.Close()
 
+	tax := s.Taxonomy()
 	var tags []PopularTag
 	for rows.Next() {
 		var tag PopularTag
-		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+		if err := rows.Scan(&tag.Name, &tag.Display, &tag.Count); err != nil {
 			return nil, err
 		}
-		tag.Category = categorizeTag(tag.Name)
+		tag.Category = tax.Categorize(tag.Name)
+		if c := tax.Category(tag.Category); c != nil {
+			tag.Color, tag.Background = c.Color, c.Background
+		}
 		tags = append(tags, tag)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-	return tags, rows.Err()
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tax.Order(tags[i].Category) < tax.Order(tags[j].Category)
+	})
+	weighTags(tags)
+	return tags, nil
 }
 
-// categorizeTag determines the category of a tag based on its name
-func categorizeTag(tagName string) string {
-	if len(tagName) > 15 && tagName[:15] == "cognitive-skill" {
-		return "cognitive"
-	}
-	if len(tagName) > 4 && tagName[:4] == "bias" {
-		return "bias"
+// weighTags assigns tag cloud weights on a log scale between the least and
+// most used tags, so one very popular tag does not flatten all the others
+func weighTags(tags []PopularTag) {
+	if len(tags) == 0 {
+		return
 	}
-	// Technical tags are programming languages, frameworks, tools
-	technicalTags := map[string]bool{
-		"go": true, "javascript": true, "typescript": true, "python": true, "rust": true,
-		"react": true, "htmx": true, "sqlite": true, "docker": true, "rails": true,
-		"architecture": true, "backend": true, "frontend": true, "api": true, "database": true,
-		"performance": true, "security": true, "testing": true, "deployment": true,
+	min, max := tags[0].Count, tags[0].Count
+	for _, t := range tags {
+		if t.Count < min {
+			min = t.Count
+		}
+		if t.Count > max {
+			max = t.Count
+		}
 	}
-	if technicalTags[tagName] {
-		return "technical"
+
+	spread := math.Log(float64(max)) - math.Log(float64(min))
+	for i := range tags {
+		if spread == 0 {
+			tags[i].Weight = 1
+			continue
+		}
+		scaled := (math.Log(float64(tags[i].Count)) - math.Log(float64(min))) / spread
+		tags[i].Weight = 1 + int(math.Round(scaled*(tagCloudLevels-1)))
 	}
-	return "general"
 }
 
 // CategoryGroup represents a group of tags by category
 type CategoryGroup struct {
-	Category string
-	Tags     []PopularTag
+	Category   string
+	Title      string // Display name from the taxonomy
+	Color      string
+	Background string
+	Tags       []PopularTag
 }
 
-// GetCategorizedTags returns popular tags grouped by category
+// GetCategorizedTags returns popular tags grouped by category, in the
+// taxonomy's category order
 func (s *Store) GetCategorizedTags(limit int) ([]CategoryGroup, error) {
 	tags, err := s.GetPopularTags(limit)
 	if err != nil {
 		return nil, err
 	}
-	
-	// Group tags by category
-	categoryMap := make(map[string][]PopularTag)
-	for _, tag := range tags {
-		categoryMap[tag.Category] = append(categoryMap[tag.Category], tag)
-	}
-	
-	// Convert to ordered slice - prioritize cognitive and bias first
+
+	// Tags arrive sorted by category, so each group is a contiguous run
+	tax := s.Taxonomy()
 	var groups []CategoryGroup
-	categoryOrder := []string{"cognitive", "bias", "technical", "general"}
-	
-	for _, category := range categoryOrder {
-		if tagList, exists := categoryMap[category]; exists && len(tagList) > 0 {
-			groups = append(groups, CategoryGroup{
-				Category: category,
-				Tags:     tagList,
-			})
+	for _, tag := range tags {
+		if n := len(groups); n > 0 && groups[n-1].Category == tag.Category {
+			groups[n-1].Tags = append(groups[n-1].Tags, tag)
+			continue
+		}
+		group := CategoryGroup{Category: tag.Category, Title: tag.Category, Tags: []PopularTag{tag}}
+		if c := tax.Category(tag.Category); c != nil {
+			group.Title, group.Color, group.Background = c.Title, c.Color, c.Background
 		}
+		groups = append(groups, group)
 	}
-	
+
 	return groups, nil
 }
 
-// GetPostsByTag returns all posts that have the specified tag
+// GetPostsByTag returns all posts that have the specified tag or one of its
+// descendants, e.g. cognitive-skill:analysis for cognitive-skill
 func (s *Store) GetPostsByTag(tagName string) ([]*Post, error) {
 	query := `
-		SELECT p.id, p.slug, p.title, p.summary, p.html, p.raw_md, 
-		       p.published_at, p.updated_at, p.draft
+		WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM tags WHERE name = ?
+			UNION
+			SELECT t.id FROM tags t JOIN subtree ON t.parent_id = subtree.id
+		)
+		SELECT ` + postColumns + `
 		FROM posts p
-		JOIN post_tags pt ON p.id = pt.post_id
-		JOIN tags t ON pt.tag_id = t.id
-		WHERE t.name = ? 
+		WHERE p.id IN (
+			SELECT pt.post_id FROM post_tags pt WHERE pt.tag_id IN (SELECT id FROM subtree)
+		)
 		  AND p.draft = 0 
 		  AND p.published_at <= datetime('now')
 		ORDER BY p.published_at DESC
 	`
 	
-	rows, err := s.db.Query(query, tagName)
+	rows, err := s.db.Query(query, taxonomy.Normalize(tagName))
 	if err != nil {
 		return nil, err
 	}
@@ -491,22 +949,15 @@ func (s *Store) GetPostsByTag(tagName string) ([]*Post, error) {
 	
 	var posts []*Post
 	for rows.Next() {
-		post := &Post{}
-		err := rows.Scan(
-			&post.ID, &post.Slug, &post.Title, &post.Summary,
-			&post.HTML, &post.RawMD, &post.PublishedAt,
-			&post.UpdatedAt, &post.Draft,
-		)
+		post, err := scanPost(rows)
 		if err != nil {
 			return nil, err
 		}
 		
 		// Load tags for this post
-		tags, err := s.getPostTags(post.ID)
-		if err != nil {
+		if err := s.loadPostTags(post); err != nil {
 			return nil, err
 		}
-		post.Tags = tags
 		
 		posts = append(posts, post)
 	}
@@ -514,30 +965,283 @@ func (s *Store) GetPostsByTag(tagName string) ([]*Post, error) {
 	return posts, rows.Err()
 }
 
-// getPostTags retrieves tags for a specific post ID
-func (s *Store) getPostTags(postID int) ([]string, error) {
+// GetBacklinks returns the published posts that link to slug, newest first.
+// A post linking to itself is not listed.
+func (s *Store) GetBacklinks(slug string) ([]*Post, error) {
+	query := "SELECT " + postColumns + ` FROM posts p
+		JOIN post_links l ON l.source_id = p.id
+		WHERE l.target_slug = ? AND p.slug != l.target_slug
+		  AND p.draft = 0
+		  AND p.published_at <= datetime('now')
+		ORDER BY p.published_at DESC`
+
+	rows, err := s.db.Query(query, slug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// GetChildTags returns the tags directly below tagName with the number of
+// published posts in each child's subtree, in name order
+func (s *Store) GetChildTags(tagName string) ([]PopularTag, error) {
 	query := `
-		SELECT t.name 
+		WITH RECURSIVE closure(ancestor, descendant) AS (
+			SELECT id, id FROM tags
+			UNION
+			SELECT closure.ancestor, t.id FROM tags t JOIN closure ON t.parent_id = closure.descendant
+		)
+		SELECT child.name, child.display_name, COUNT(DISTINCT p.id)
+		FROM tags child
+		JOIN tags parent ON child.parent_id = parent.id
+		JOIN closure ON closure.ancestor = child.id
+		JOIN post_tags pt ON pt.tag_id = closure.descendant
+		JOIN posts p ON p.id = pt.post_id
+		WHERE parent.name = ?
+		  AND p.draft = 0
+		  AND p.published_at <= datetime('now')
+		GROUP BY child.id
+		ORDER BY child.name ASC
+	`
+
+	rows, err := s.db.Query(query, taxonomy.Normalize(tagName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tax := s.Taxonomy()
+	var tags []PopularTag
+	for rows.Next() {
+		var tag PopularTag
+		if err := rows.Scan(&tag.Name, &tag.Display, &tag.Count); err != nil {
+			return nil, err
+		}
+		tag.Category = tax.Categorize(tag.Name)
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// loadPostTags sets the tags of a post and their display names
+func (s *Store) loadPostTags(p *Post) error {
+	query := `
+		SELECT t.name, t.display_name
 		FROM tags t
 		JOIN post_tags pt ON t.id = pt.tag_id
 		WHERE pt.post_id = ?
 		ORDER BY t.name
 	`
 	
-	rows, err := s.db.Query(query, postID)
+	rows, err := s.db.Query(query, p.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 	
-	var tags []string
+	p.Tags, p.TagNames = nil, make(map[string]string)
 	for rows.Next() {
-		var tag string
-		if err := rows.Scan(&tag); err != nil {
-			return nil, err
+		var tag, display string
+		if err := rows.Scan(&tag, &display); err != nil {
+			return err
 		}
-		tags = append(tags, tag)
+		p.Tags = append(p.Tags, tag)
+		p.TagNames[tag] = display
 	}
 	
-	return tags, rows.Err()
-}
\ No newline at end of file
+	return rows.Err()
+}
+
+// UpsertSections replaces the stored sections with the loaded set: sections
+// are upserted and any whose directory is gone are deleted
+func (s *Store) UpsertSections(sections []*Section) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO sections (name, title, summary, intro_html)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			title = excluded.title,
+			summary = excluded.summary,
+			intro_html = excluded.intro_html
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	names := make([]interface{}, len(sections))
+	for i, section := range sections {
+		if _, err := stmt.Exec(section.Name, section.Title, section.Summary, section.IntroHTML); err != nil {
+			return err
+		}
+		names[i] = section.Name
+	}
+
+	query := "DELETE FROM sections"
+	if len(names) > 0 {
+		query += " WHERE name NOT IN (?" + strings.Repeat(", ?", len(names)-1) + ")"
+	}
+	if _, err := tx.Exec(query, names...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetSections returns all sections with their published post counts
+func (s *Store) GetSections() ([]Section, error) {
+	query := `
+		SELECT s.name, s.title, s.summary, s.intro_html, COUNT(p.id)
+		FROM sections s
+		LEFT JOIN posts p ON p.section = s.name AND p.draft = 0
+		GROUP BY s.name
+		ORDER BY s.name ASC
+	`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sections []Section
+	for rows.Next() {
+		var sec Section
+		if err := rows.Scan(&sec.Name, &sec.Title, &sec.Summary, &sec.IntroHTML, &sec.PostCount); err != nil {
+			return nil, err
+		}
+		sections = append(sections, sec)
+	}
+
+	return sections, rows.Err()
+}
+
+// GetSection returns a section by name, or nil if it does not exist
+func (s *Store) GetSection(name string) (*Section, error) {
+	query := `
+		SELECT s.name, s.title, s.summary, s.intro_html, COUNT(p.id)
+		FROM sections s
+		LEFT JOIN posts p ON p.section = s.name AND p.draft = 0
+		WHERE s.name = ?
+		GROUP BY s.name
+	`
+
+	var sec Section
+	err := s.db.QueryRow(query, name).Scan(&sec.Name, &sec.Title, &sec.Summary, &sec.IntroHTML, &sec.PostCount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &sec, nil
+}
+
+// GetPostsBySection returns the posts in a section, newest first
+func (s *Store) GetPostsBySection(section string, includeDrafts bool) ([]*Post, error) {
+	query := "SELECT " + postColumns + " FROM posts p WHERE p.section = ?"
+	if !includeDrafts {
+		query += " AND p.draft = 0"
+	}
+	query += " ORDER BY p.published_at DESC"
+
+	rows, err := s.db.Query(query, section)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Load tags once the result set is closed
+	for _, post := range posts {
+		if err := s.loadPostTags(post); err != nil {
+			return nil, err
+		}
+	}
+
+	return posts, nil
+}
+
+// GetAdjacentPosts returns the published posts immediately before and after p
+// in publication order. Either is nil at the ends of the archive.
+func (s *Store) GetAdjacentPosts(p *Post) (prev, next *Post, err error) {
+	prevQuery := "SELECT " + postColumns + ` FROM posts p
+		WHERE p.draft = 0 AND p.id != ?
+		  AND (p.published_at < ? OR (p.published_at = ? AND p.id < ?))
+		ORDER BY p.published_at DESC, p.id DESC
+		LIMIT 1`
+	nextQuery := "SELECT " + postColumns + ` FROM posts p
+		WHERE p.draft = 0 AND p.id != ?
+		  AND (p.published_at > ? OR (p.published_at = ? AND p.id > ?))
+		ORDER BY p.published_at ASC, p.id ASC
+		LIMIT 1`
+
+	prev, err = scanPost(s.db.QueryRow(prevQuery, p.ID, p.PublishedAt, p.PublishedAt, p.ID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, nil, err
+	}
+	next, err = scanPost(s.db.QueryRow(nextQuery, p.ID, p.PublishedAt, p.PublishedAt, p.ID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, nil, err
+	}
+
+	return prev, next, nil
+}
+
+// GetSeries returns the published parts of a series in reading order:
+// by series_order, then by publication date for parts without one
+func (s *Store) GetSeries(name string) ([]*Post, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	query := "SELECT " + postColumns + ` FROM posts p
+		WHERE p.series = ? AND p.draft = 0
+		ORDER BY p.series_order = 0, p.series_order ASC, p.published_at ASC`
+
+	rows, err := s.db.Query(query, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
@@ -1,8 +1,13 @@
 package store
 
 import (
+	"database/sql"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestStoreMigrations(t *testing.T) {
@@ -44,6 +49,32 @@ func TestStoreMigrations(t *testing.T) {
 	}
 }
 
+func TestMigrationFailureRollsBack(t *testing.T) {
+	store := MustOpen(filepath.Join(t.TempDir(), "rollback.db"))
+	defer store.Close()
+
+	failing := Migration{
+		Version: "999_test",
+		SQL:     "ALTER TABLE posts ADD COLUMN extra TEXT",
+		Run:     func(tx *sql.Tx) error { return fmt.Errorf("data migration failed") },
+	}
+	if err := store.applyMigration(failing); err == nil {
+		t.Fatal("Expected the failing data migration to return an error")
+	}
+	if applied, err := store.isMigrationApplied(failing.Version); err != nil || applied {
+		t.Errorf("Expected the failed migration unrecorded, got %v (%v)", applied, err)
+	}
+
+	// The ALTER was rolled back too, so a retry applies cleanly
+	failing.Run = nil
+	if err := store.applyMigration(failing); err != nil {
+		t.Fatalf("Expected the retried migration to apply, got %v", err)
+	}
+	if applied, _ := store.isMigrationApplied(failing.Version); !applied {
+		t.Error("Expected the retried migration recorded")
+	}
+}
+
 func TestBasicPostOperations(t *testing.T) {
 	// Create temporary database
 	tempDB := "test_posts.db"
@@ -107,4 +138,692 @@ func TestBasicPostOperations(t *testing.T) {
 	if len(posts) != 1 {
 		t.Errorf("Expected 1 post, got %d", len(posts))
 	}
-}
\ No newline at end of file
+}
+func TestSections(t *testing.T) {
+	store := MustOpen(filepath.Join(t.TempDir(), "sections.db"))
+	defer store.Close()
+
+	posts := []*Post{
+		{Slug: "root", Title: "Root", HTML: "<p>r</p>", RawMD: "r", PublishedAt: "2025-09-10T10:00:00Z", UpdatedAt: "2025-09-10T10:00:00Z"},
+		{Slug: "one", Title: "One", HTML: "<p>1</p>", RawMD: "1", PublishedAt: "2025-09-11T10:00:00Z", UpdatedAt: "2025-09-11T10:00:00Z", Section: "projects", Tags: []string{"go"}},
+		{Slug: "two", Title: "Two", HTML: "<p>2</p>", RawMD: "2", PublishedAt: "2025-09-12T10:00:00Z", UpdatedAt: "2025-09-12T10:00:00Z", Section: "projects"},
+		{Slug: "wip", Title: "WIP", HTML: "<p>w</p>", RawMD: "w", PublishedAt: "2025-09-13T10:00:00Z", UpdatedAt: "2025-09-13T10:00:00Z", Section: "projects", Draft: true},
+	}
+	if err := store.UpsertPosts(posts); err != nil {
+		t.Fatalf("UpsertPosts failed: %v", err)
+	}
+
+	sections := []*Section{
+		{Name: "projects", Title: "Projects", Summary: "Things I built", IntroHTML: "<p>Intro</p>"},
+		{Name: "til", Title: "TIL"},
+	}
+	if err := store.UpsertSections(sections); err != nil {
+		t.Fatalf("UpsertSections failed: %v", err)
+	}
+
+	all, err := store.GetSections()
+	if err != nil {
+		t.Fatalf("GetSections failed: %v", err)
+	}
+	if len(all) != 2 || all[0].Name != "projects" || all[0].PostCount != 2 || all[1].PostCount != 0 {
+		t.Errorf("Unexpected sections: %+v", all)
+	}
+
+	section, err := store.GetSection("projects")
+	if err != nil {
+		t.Fatalf("GetSection failed: %v", err)
+	}
+	if section == nil || section.IntroHTML != "<p>Intro</p>" {
+		t.Errorf("Expected projects section with intro, got %+v", section)
+	}
+
+	missing, err := store.GetSection("nope")
+	if err != nil {
+		t.Fatalf("GetSection failed: %v", err)
+	}
+	if missing != nil {
+		t.Error("Expected nil for unknown section")
+	}
+
+	sectionPosts, err := store.GetPostsBySection("projects", false)
+	if err != nil {
+		t.Fatalf("GetPostsBySection failed: %v", err)
+	}
+	if len(sectionPosts) != 2 || sectionPosts[0].Slug != "two" {
+		t.Fatalf("Expected 2 published projects posts, newest first, got %d", len(sectionPosts))
+	}
+	if sectionPosts[1].Section != "projects" || len(sectionPosts[1].Tags) != 1 {
+		t.Errorf("Expected section and tags to be loaded, got %+v", sectionPosts[1])
+	}
+
+	withDrafts, err := store.GetPostsBySection("projects", true)
+	if err != nil {
+		t.Fatalf("GetPostsBySection failed: %v", err)
+	}
+	if len(withDrafts) != 3 {
+		t.Errorf("Expected 3 posts including drafts, got %d", len(withDrafts))
+	}
+
+	// A section whose directory is gone is removed on the next upsert
+	if err := store.UpsertSections(sections[:1]); err != nil {
+		t.Fatalf("UpsertSections failed: %v", err)
+	}
+	if all, _ := store.GetSections(); len(all) != 1 || all[0].Name != "projects" {
+		t.Errorf("Expected only projects to remain, got %+v", all)
+	}
+	if til, _ := store.GetSection("til"); til != nil {
+		t.Errorf("Expected til deleted, got %+v", til)
+	}
+}
+
+func TestPostMetadataRoundTrip(t *testing.T) {
+	store := MustOpen(filepath.Join(t.TempDir(), "toc.db"))
+	defer store.Close()
+
+	post := &Post{
+		Slug:        "long-post",
+		Title:       "Long Post",
+		HTML:        "<h2 id=\"a\">A</h2>",
+		RawMD:       "## A",
+		PublishedAt: "2025-09-15T00:00:00Z",
+		UpdatedAt:   "2025-09-15T00:00:00Z",
+		ShowTOC:     true,
+		WordCount:   420,
+		ReadingTime: 3,
+		Excerpt:     "First paragraph.",
+		TOC: []Heading{
+			{Level: 2, ID: "a", Text: "A", Children: []Heading{{Level: 3, ID: "a-1", Text: "A.1"}}},
+		},
+	}
+	if err := store.UpsertPosts([]*Post{post}); err != nil {
+		t.Fatalf("UpsertPosts failed: %v", err)
+	}
+
+	got, err := store.GetPostBySlug("long-post")
+	if err != nil {
+		t.Fatalf("GetPostBySlug failed: %v", err)
+	}
+	if !got.ShowTOC {
+		t.Error("Expected ShowTOC to persist")
+	}
+	if len(got.TOC) != 1 || len(got.TOC[0].Children) != 1 || got.TOC[0].Children[0].ID != "a-1" {
+		t.Errorf("Expected heading tree to round-trip, got %+v", got.TOC)
+	}
+	if got.WordCount != 420 || got.ReadingTime != 3 || got.Excerpt != "First paragraph." {
+		t.Errorf("Expected reading stats to persist, got %d words, %d min, %q", got.WordCount, got.ReadingTime, got.Excerpt)
+	}
+}
+
+func TestGetRelatedPosts(t *testing.T) {
+	store := MustOpen(filepath.Join(t.TempDir(), "related.db"))
+	defer store.Close()
+
+	newPost := func(slug, title string, tags ...string) *Post {
+		return &Post{
+			Slug:        slug,
+			Title:       title,
+			HTML:        "<p>" + title + "</p>",
+			RawMD:       title,
+			PublishedAt: "2025-09-01T00:00:00Z",
+			UpdatedAt:   "2025-09-01T00:00:00Z",
+			Tags:        tags,
+		}
+	}
+
+	posts := []*Post{
+		newPost("source", "Building a blog engine", "go", "sqlite", "meta"),
+		newPost("rare-tags", "Schema migrations", "go", "sqlite"),
+		newPost("common-tag", "Weekly notes", "meta"),
+		newPost("text-only", "Blog engine performance"),
+		newPost("unrelated", "Gardening"),
+		newPost("also-meta", "Monthly notes", "meta"),
+	}
+	draft := newPost("draft", "Draft about sqlite", "go", "sqlite")
+	draft.Draft = true
+	posts = append(posts, draft)
+
+	if err := store.UpsertPosts(posts); err != nil {
+		t.Fatalf("UpsertPosts failed: %v", err)
+	}
+
+	source, err := store.GetPostBySlug("source")
+	if err != nil {
+		t.Fatalf("GetPostBySlug failed: %v", err)
+	}
+	if len(source.Tags) != 3 {
+		t.Errorf("Expected GetPostBySlug to load tags, got %v", source.Tags)
+	}
+
+	related, err := store.GetRelatedPosts(source.ID, 3)
+	if err != nil {
+		t.Fatalf("GetRelatedPosts failed: %v", err)
+	}
+
+	var slugs []string
+	for _, p := range related {
+		slugs = append(slugs, p.Slug)
+	}
+	if len(slugs) != 3 {
+		t.Fatalf("Expected 3 related posts, got %v", slugs)
+	}
+
+	// Two rare shared tags outrank one common tag; shared title terms count too
+	if slugs[0] != "rare-tags" {
+		t.Errorf("Expected rare-tags first, got %v", slugs)
+	}
+	for _, slug := range slugs {
+		if slug == "unrelated" || slug == "draft" || slug == "source" {
+			t.Errorf("Unexpected related post %s in %v", slug, slugs)
+		}
+	}
+	if len(related[0].Tags) != 2 {
+		t.Errorf("Expected related posts to carry tags, got %v", related[0].Tags)
+	}
+
+	// Title overlap alone is enough to be related; nothing at all is not
+	related, err = store.GetRelatedPosts(source.ID, 10)
+	if err != nil {
+		t.Fatalf("GetRelatedPosts failed: %v", err)
+	}
+	found := map[string]bool{}
+	for _, p := range related {
+		found[p.Slug] = true
+	}
+	if !found["text-only"] {
+		t.Error("Expected text-only to be related by shared title terms")
+	}
+	if found["unrelated"] {
+		t.Error("Expected unrelated to be excluded")
+	}
+}
+
+func TestSeriesAndAdjacentPosts(t *testing.T) {
+	store := MustOpen(filepath.Join(t.TempDir(), "series.db"))
+	defer store.Close()
+
+	newPost := func(slug, date, series string, order int) *Post {
+		return &Post{
+			Slug:        slug,
+			Title:       slug,
+			HTML:        "<p>" + slug + "</p>",
+			RawMD:       slug,
+			PublishedAt: date,
+			UpdatedAt:   date,
+			Series:      series,
+			SeriesOrder: order,
+		}
+	}
+
+	posts := []*Post{
+		newPost("part-two", "2025-09-01T00:00:00Z", "Building Notebook", 2),
+		newPost("part-one", "2025-09-03T00:00:00Z", "Building Notebook", 1),
+		newPost("unordered", "2025-09-02T00:00:00Z", "Building Notebook", 0),
+		newPost("standalone", "2025-09-04T00:00:00Z", "", 0),
+	}
+	draft := newPost("draft-part", "2025-09-05T00:00:00Z", "Building Notebook", 3)
+	draft.Draft = true
+	posts = append(posts, draft)
+
+	if err := store.UpsertPosts(posts); err != nil {
+		t.Fatalf("UpsertPosts failed: %v", err)
+	}
+
+	parts, err := store.GetSeries("Building Notebook")
+	if err != nil {
+		t.Fatalf("GetSeries failed: %v", err)
+	}
+	var order []string
+	for _, p := range parts {
+		order = append(order, p.Slug)
+	}
+	// Explicit order first, unordered parts after by date, drafts excluded
+	if strings.Join(order, ",") != "part-one,part-two,unordered" {
+		t.Errorf("Unexpected series order %v", order)
+	}
+
+	middle, err := store.GetPostBySlug("unordered")
+	if err != nil {
+		t.Fatalf("GetPostBySlug failed: %v", err)
+	}
+	if middle.Series != "Building Notebook" {
+		t.Errorf("Expected series to round-trip, got %q", middle.Series)
+	}
+
+	prev, next, err := store.GetAdjacentPosts(middle)
+	if err != nil {
+		t.Fatalf("GetAdjacentPosts failed: %v", err)
+	}
+	if prev == nil || prev.Slug != "part-two" {
+		t.Errorf("Expected prev part-two, got %+v", prev)
+	}
+	if next == nil || next.Slug != "part-one" {
+		t.Errorf("Expected next part-one, got %+v", next)
+	}
+
+	newest, _ := store.GetPostBySlug("standalone")
+	_, next, err = store.GetAdjacentPosts(newest)
+	if err != nil {
+		t.Fatalf("GetAdjacentPosts failed: %v", err)
+	}
+	if next != nil {
+		t.Errorf("Expected no next post past the newest published post, got %s", next.Slug)
+	}
+}
+
+func TestArchive(t *testing.T) {
+	store := MustOpen(filepath.Join(t.TempDir(), "archive.db"))
+	defer store.Close()
+
+	newPost := func(slug, date string) *Post {
+		return &Post{Slug: slug, Title: slug, HTML: "<p></p>", RawMD: "", PublishedAt: date, UpdatedAt: date}
+	}
+	posts := []*Post{
+		newPost("sep-a", "2025-09-12T10:00:00Z"),
+		newPost("sep-b", "2025-09-20T10:00:00Z"),
+		newPost("oct-local", "2025-09-30T23:30:00Z"), // October 1st in Berlin
+		newPost("last-year", "2024-12-01T10:00:00Z"),
+	}
+	draft := newPost("draft", "2025-08-01T10:00:00Z")
+	draft.Draft = true
+	posts = append(posts, draft)
+	if err := store.UpsertPosts(posts); err != nil {
+		t.Fatalf("UpsertPosts failed: %v", err)
+	}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("zone data unavailable: %v", err)
+	}
+
+	years, err := store.GetArchive(berlin)
+	if err != nil {
+		t.Fatalf("GetArchive failed: %v", err)
+	}
+	if len(years) != 2 || years[0].Year != 2025 || years[1].Year != 2024 {
+		t.Fatalf("Expected 2025 then 2024, got %+v", years)
+	}
+	if years[0].Count != 3 || len(years[0].Months) != 2 {
+		t.Fatalf("Expected 3 posts over 2 months in 2025, got %+v", years[0])
+	}
+	if m := years[0].Months[0]; m.Month != time.October || m.Count != 1 {
+		t.Errorf("Expected October first with 1 post, got %+v", m)
+	}
+	if m := years[0].Months[1]; m.Month != time.September || m.Count != 2 {
+		t.Errorf("Expected September with 2 posts, got %+v", m)
+	}
+
+	sep, err := store.GetPostsByPeriod(2025, time.September, berlin)
+	if err != nil {
+		t.Fatalf("GetPostsByPeriod failed: %v", err)
+	}
+	if len(sep) != 2 || sep[0].Slug != "sep-b" {
+		t.Errorf("Expected sep-b and sep-a, got %d posts", len(sep))
+	}
+
+	all, err := store.GetPostsByPeriod(2025, 0, berlin)
+	if err != nil {
+		t.Fatalf("GetPostsByPeriod failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Expected 3 published posts in 2025, got %d", len(all))
+	}
+}
+
+func TestWeighTags(t *testing.T) {
+	tags := []PopularTag{{Name: "a", Count: 40}, {Name: "b", Count: 6}, {Name: "c", Count: 1}}
+	weighTags(tags)
+
+	if tags[0].Weight != tagCloudLevels || tags[2].Weight != 1 {
+		t.Errorf("Expected weights to span 1..%d, got %d and %d", tagCloudLevels, tags[0].Weight, tags[2].Weight)
+	}
+	// Log scale: 6 of 40 sits in the middle, not near the bottom
+	if tags[1].Weight != 3 {
+		t.Errorf("Expected log-scaled middle weight 3, got %d", tags[1].Weight)
+	}
+
+	same := []PopularTag{{Name: "a", Count: 2}, {Name: "b", Count: 2}}
+	weighTags(same)
+	if same[0].Weight != 1 || same[1].Weight != 1 {
+		t.Errorf("Expected equal counts to share the smallest weight, got %+v", same)
+	}
+}
+
+func TestTagHierarchy(t *testing.T) {
+	store := MustOpen(filepath.Join(t.TempDir(), "hierarchy.db"))
+	defer store.Close()
+
+	posts := []*Post{
+		{Slug: "analysis", Title: "Analysis", HTML: "<p>a</p>", RawMD: "a",
+			PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-01T00:00:00Z",
+			Tags: []string{"cognitive-skill:analysis"}},
+		{Slug: "deep", Title: "Deep", HTML: "<p>d</p>", RawMD: "d",
+			PublishedAt: "2025-09-02T00:00:00Z", UpdatedAt: "2025-09-02T00:00:00Z",
+			Tags: []string{"cognitive-skill:analysis:deep"}},
+		{Slug: "synthesis", Title: "Synthesis", HTML: "<p>s</p>", RawMD: "s",
+			PublishedAt: "2025-09-03T00:00:00Z", UpdatedAt: "2025-09-03T00:00:00Z",
+			Tags: []string{"cognitive-skill:synthesis", "go"}},
+	}
+	if err := store.UpsertPosts(posts); err != nil {
+		t.Fatalf("UpsertPosts failed: %v", err)
+	}
+
+	// Parents are created and linked even though no post uses them directly
+	root, err := store.GetOrCreateTag("cognitive-skill")
+	if err != nil {
+		t.Fatalf("GetOrCreateTag failed: %v", err)
+	}
+	if root.ParentID != 0 {
+		t.Errorf("Expected top-level tag, got parent %d", root.ParentID)
+	}
+	analysis, err := store.GetOrCreateTag("cognitive-skill:analysis")
+	if err != nil {
+		t.Fatalf("GetOrCreateTag failed: %v", err)
+	}
+	if analysis.ParentID != root.ID {
+		t.Errorf("Expected parent %d, got %d", root.ID, analysis.ParentID)
+	}
+
+	slugs := func(tag string) string {
+		posts, err := store.GetPostsByTag(tag)
+		if err != nil {
+			t.Fatalf("GetPostsByTag(%q) failed: %v", tag, err)
+		}
+		var out []string
+		for _, p := range posts {
+			out = append(out, p.Slug)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := slugs("cognitive-skill"); got != "synthesis,deep,analysis" {
+		t.Errorf("Parent page posts = %q", got)
+	}
+	if got := slugs("cognitive-skill:analysis"); got != "deep,analysis" {
+		t.Errorf("Child page posts = %q", got)
+	}
+	if got := slugs("cognitive-skill:analysis:deep"); got != "deep" {
+		t.Errorf("Leaf page posts = %q", got)
+	}
+
+	children, err := store.GetChildTags("cognitive-skill")
+	if err != nil {
+		t.Fatalf("GetChildTags failed: %v", err)
+	}
+	if len(children) != 2 ||
+		children[0].Name != "cognitive-skill:analysis" || children[0].Count != 2 ||
+		children[1].Name != "cognitive-skill:synthesis" || children[1].Count != 1 {
+		t.Errorf("Unexpected child tags %+v", children)
+	}
+}
+
+func TestTagNormalisation(t *testing.T) {
+	store := MustOpen(filepath.Join(t.TempDir(), "normalise.db"))
+	defer store.Close()
+
+	posts := []*Post{
+		{Slug: "first", Title: "First", HTML: "<p>1</p>", RawMD: "1",
+			PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-01T00:00:00Z",
+			Tags: []string{"Go", " go", "Sunk Cost"}},
+		{Slug: "second", Title: "Second", HTML: "<p>2</p>", RawMD: "2",
+			PublishedAt: "2025-09-02T00:00:00Z", UpdatedAt: "2025-09-02T00:00:00Z",
+			Tags: []string{"GO"}},
+	}
+	if err := store.UpsertPosts(posts); err != nil {
+		t.Fatalf("UpsertPosts failed: %v", err)
+	}
+
+	tags, err := store.GetPopularTags(0)
+	if err != nil {
+		t.Fatalf("GetPopularTags failed: %v", err)
+	}
+	if len(tags) != 2 || tags[0].Name != "go" || tags[0].Count != 2 {
+		t.Fatalf("Expected spellings of go merged, got %+v", tags)
+	}
+	if tags[0].Display != "Go" {
+		t.Errorf("Expected first-seen display name, got %q", tags[0].Display)
+	}
+
+	post, err := store.GetPostBySlug("first")
+	if err != nil || post == nil {
+		t.Fatalf("GetPostBySlug failed: %v", err)
+	}
+	if strings.Join(post.Tags, ",") != "go,sunk-cost" || post.TagName("sunk-cost") != "Sunk Cost" {
+		t.Errorf("Unexpected post tags %v %v", post.Tags, post.TagNames)
+	}
+
+	// Lookups accept any spelling
+	if byTag, _ := store.GetPostsByTag("Go"); len(byTag) != 2 {
+		t.Errorf("Expected 2 posts for Go, got %d", len(byTag))
+	}
+	if tag, _ := store.GetTag(" SUNK cost"); tag == nil || tag.Name != "sunk-cost" {
+		t.Errorf("GetTag = %+v", tag)
+	}
+}
+
+func TestMergeDuplicateTags(t *testing.T) {
+	store := MustOpen(filepath.Join(t.TempDir(), "merge.db"))
+	defer store.Close()
+
+	if err := store.UpsertPost(&Post{Slug: "a", Title: "A", HTML: "<p></p>", RawMD: "",
+		PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-01T00:00:00Z"}); err != nil {
+		t.Fatalf("UpsertPost failed: %v", err)
+	}
+	if err := store.UpsertPost(&Post{Slug: "b", Title: "B", HTML: "<p></p>", RawMD: "",
+		PublishedAt: "2025-09-02T00:00:00Z", UpdatedAt: "2025-09-02T00:00:00Z"}); err != nil {
+		t.Fatalf("UpsertPost failed: %v", err)
+	}
+
+	// Tags as verbatim inserts left them before normalisation
+	for _, stmt := range []string{
+		`INSERT INTO tags (id, name, display_name) VALUES (1, 'Go', 'Go'), (2, 'go', 'go'), (3, ' go', ' go'), (4, '!!', '!!'), (5, 'Sunk Cost', 'Sunk Cost')`,
+		`INSERT INTO post_tags (post_id, tag_id) SELECT id, 1 FROM posts WHERE slug = 'a'`,
+		`INSERT INTO post_tags (post_id, tag_id) SELECT id, 2 FROM posts WHERE slug = 'a'`,
+		`INSERT INTO post_tags (post_id, tag_id) SELECT id, 3 FROM posts WHERE slug = 'b'`,
+		`INSERT INTO post_tags (post_id, tag_id) SELECT id, 4 FROM posts WHERE slug = 'b'`,
+	} {
+		if _, err := store.db.Exec(stmt); err != nil {
+			t.Fatalf("Seeding failed: %v", err)
+		}
+	}
+
+	if err := store.applyMigration(Migration{Version: "test_merge", Run: mergeDuplicateTags}); err != nil {
+		t.Fatalf("mergeDuplicateTags failed: %v", err)
+	}
+
+	rows, err := store.db.Query("SELECT id, name, display_name FROM tags ORDER BY id")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var id int
+		var name, display string
+		if err := rows.Scan(&id, &name, &display); err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		got = append(got, fmt.Sprintf("%d:%s:%s", id, name, display))
+	}
+	if strings.Join(got, ",") != "1:go:Go,5:sunk-cost:Sunk Cost" {
+		t.Errorf("Unexpected tags after merge: %v", got)
+	}
+
+	posts, err := store.GetPostsByTag("go")
+	if err != nil {
+		t.Fatalf("GetPostsByTag failed: %v", err)
+	}
+	if len(posts) != 2 {
+		t.Errorf("Expected both posts on the merged tag, got %d", len(posts))
+	}
+}
+
+func TestUpsertPostsSyncsTagsExactly(t *testing.T) {
+	store := MustOpen(filepath.Join(t.TempDir(), "tagsync.db"))
+	defer store.Close()
+
+	newPost := func(slug string, tags ...string) *Post {
+		return &Post{
+			Slug: slug, Title: slug, HTML: "<p>" + slug + "</p>", RawMD: slug,
+			PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-01T00:00:00Z",
+			Tags: tags,
+		}
+	}
+	tagNames := func() string {
+		rows, err := store.db.Query("SELECT name FROM tags ORDER BY name")
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		defer rows.Close()
+		var names []string
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+			names = append(names, name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	err := store.UpsertPosts([]*Post{
+		newPost("a", "go", "bias:anchoring", "meta"),
+		newPost("b", "go"),
+	})
+	if err != nil {
+		t.Fatalf("UpsertPosts failed: %v", err)
+	}
+	if got := tagNames(); got != "bias,bias:anchoring,go,meta" {
+		t.Fatalf("Unexpected tags %q", got)
+	}
+
+	// Dropping one tag unlinks it and deletes it once nothing uses it
+	if err := store.UpsertPosts([]*Post{newPost("a", "go", "bias:anchoring")}); err != nil {
+		t.Fatalf("UpsertPosts failed: %v", err)
+	}
+	if got := tagNames(); got != "bias,bias:anchoring,go" {
+		t.Errorf("Expected meta removed, got %q", got)
+	}
+
+	// Dropping every tag clears the post's tags; go survives through post b
+	if err := store.UpsertPosts([]*Post{newPost("a")}); err != nil {
+		t.Fatalf("UpsertPosts failed: %v", err)
+	}
+	post, err := store.GetPostBySlug("a")
+	if err != nil || post == nil {
+		t.Fatalf("GetPostBySlug failed: %v", err)
+	}
+	if len(post.Tags) != 0 {
+		t.Errorf("Expected no tags after removing them all, got %v", post.Tags)
+	}
+	if got := tagNames(); got != "go" {
+		t.Errorf("Expected only go left, with the unused namespace parent gone, got %q", got)
+	}
+	if posts, _ := store.GetPostsByTag("bias:anchoring"); len(posts) != 0 {
+		t.Errorf("Expected no posts for a removed tag, got %d", len(posts))
+	}
+}
+
+func TestGetBacklinks(t *testing.T) {
+	store := MustOpen(filepath.Join(t.TempDir(), "backlinks.db"))
+	defer store.Close()
+
+	newPost := func(slug, publishedAt string, links ...string) *Post {
+		return &Post{
+			Slug: slug, Title: slug, HTML: "<p>" + slug + "</p>", RawMD: slug,
+			PublishedAt: publishedAt, UpdatedAt: publishedAt, Links: links,
+		}
+	}
+	draft := newPost("draft", "2025-09-04T00:00:00Z", "target")
+	draft.Draft = true
+
+	err := store.UpsertPosts([]*Post{
+		newPost("old", "2025-09-01T00:00:00Z", "target", "missing"),
+		newPost("new", "2025-09-03T00:00:00Z", "target"),
+		newPost("target", "2025-09-02T00:00:00Z", "target", "old"),
+		draft,
+	})
+	if err != nil {
+		t.Fatalf("UpsertPosts failed: %v", err)
+	}
+
+	slugs := func(posts []*Post) string {
+		var s []string
+		for _, p := range posts {
+			s = append(s, p.Slug)
+		}
+		return strings.Join(s, ",")
+	}
+
+	// Newest first, leaving out drafts and the post's link to itself
+	backlinks, err := store.GetBacklinks("target")
+	if err != nil {
+		t.Fatalf("GetBacklinks failed: %v", err)
+	}
+	if got := slugs(backlinks); got != "new,old" {
+		t.Errorf("Expected backlinks new,old, got %q", got)
+	}
+
+	// Re-upserting replaces a post's links
+	if err := store.UpsertPosts([]*Post{newPost("new", "2025-09-03T00:00:00Z")}); err != nil {
+		t.Fatalf("UpsertPosts failed: %v", err)
+	}
+	backlinks, err = store.GetBacklinks("target")
+	if err != nil {
+		t.Fatalf("GetBacklinks failed: %v", err)
+	}
+	if got := slugs(backlinks); got != "old" {
+		t.Errorf("Expected only old after new dropped its link, got %q", got)
+	}
+
+	if backlinks, _ := store.GetBacklinks("old"); slugs(backlinks) != "target" {
+		t.Errorf("Expected target to link to old, got %q", slugs(backlinks))
+	}
+}
+
+func TestGetGraph(t *testing.T) {
+	store := MustOpen(filepath.Join(t.TempDir(), "graph.db"))
+	defer store.Close()
+
+	newPost := func(slug string, tags []string, links ...string) *Post {
+		return &Post{
+			Slug: slug, Title: strings.ToUpper(slug), HTML: "<p>" + slug + "</p>", RawMD: slug,
+			PublishedAt: "2025-09-01T00:00:00Z", UpdatedAt: "2025-09-01T00:00:00Z",
+			Tags: tags, Links: links,
+		}
+	}
+	draft := newPost("draft", []string{"secret"}, "a")
+	draft.Draft = true
+
+	err := store.UpsertPosts([]*Post{
+		newPost("a", []string{"bias:anchoring"}, "b", "draft", "missing", "a"),
+		newPost("b", []string{"go"}),
+		draft,
+	})
+	if err != nil {
+		t.Fatalf("UpsertPosts failed: %v", err)
+	}
+
+	graph, err := store.GetGraph()
+	if err != nil {
+		t.Fatalf("GetGraph failed: %v", err)
+	}
+
+	var nodes, edges []string
+	for _, n := range graph.Nodes {
+		nodes = append(nodes, n.ID+"="+n.Label+"@"+n.URL)
+	}
+	for _, e := range graph.Edges {
+		edges = append(edges, e.Source+">"+e.Target+"("+e.Type+")")
+	}
+
+	// Drafts and their tags are left out; namespace parents are included
+	wantNodes := "post:a=A@/p/a post:b=B@/p/b tag:bias=bias@/tag/bias tag:bias:anchoring=bias:anchoring@/tag/bias/anchoring tag:go=go@/tag/go"
+	if got := strings.Join(nodes, " "); got != wantNodes {
+		t.Errorf("Unexpected nodes:\n got %s\nwant %s", got, wantNodes)
+	}
+
+	// Only links between published posts, never to itself
+	wantEdges := "tag:bias>tag:bias:anchoring(parent) post:a>post:b(link) post:a>tag:bias:anchoring(tag) post:b>tag:go(tag)"
+	if got := strings.Join(edges, " "); got != wantEdges {
+		t.Errorf("Unexpected edges:\n got %s\nwant %s", got, wantEdges)
+	}
+}
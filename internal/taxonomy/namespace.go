@@ -0,0 +1,72 @@
+package taxonomy
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Separator splits a namespaced tag into its levels: "cognitive-skill:analysis"
+// is the child of "cognitive-skill"
+const Separator = ":"
+
+// tagURLPrefix is the route tag pages are served under
+const tagURLPrefix = "/tag/"
+
+// Parent returns the tag one level up, or "" for a top-level tag
+func Parent(tag string) string {
+	i := strings.LastIndex(tag, Separator)
+	if i <= 0 {
+		return ""
+	}
+	return tag[:i]
+}
+
+// Leaf returns the last level of a tag: "analysis" for "cognitive-skill:analysis"
+func Leaf(tag string) string {
+	return tag[strings.LastIndex(tag, Separator)+1:]
+}
+
+// Ancestors returns the tag and every tag above it, root first:
+// "a:b:c" gives ["a", "a:b", "a:b:c"]
+func Ancestors(tag string) []string {
+	out := []string{tag}
+	for p := Parent(tag); p != ""; p = Parent(p) {
+		out = append([]string{p}, out...)
+	}
+	return out
+}
+
+// TagURL returns the page URL of a tag. Each namespace level becomes a path
+// segment, so "cognitive-skill:analysis" is served at
+// /tag/cognitive-skill/analysis and no ":" appears in the URL.
+func TagURL(tag string) string {
+	parts := strings.Split(tag, Separator)
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return tagURLPrefix + strings.Join(parts, "/")
+}
+
+// ParseTagPath recovers a tag from an escaped /tag/... path built by TagURL.
+// Legacy URLs that spell the separator out (/tag/cognitive-skill:analysis)
+// parse to the same tag.
+func ParseTagPath(escapedPath string) (string, bool) {
+	rest := strings.TrimPrefix(escapedPath, tagURLPrefix)
+	if rest == escapedPath {
+		return "", false
+	}
+	rest = strings.TrimSuffix(rest, "/")
+	if rest == "" {
+		return "", false
+	}
+
+	segments := strings.Split(rest, "/")
+	for i, seg := range segments {
+		decoded, err := url.PathUnescape(seg)
+		if err != nil || decoded == "" {
+			return "", false
+		}
+		segments[i] = decoded
+	}
+	return strings.Join(segments, Separator), true
+}
@@ -0,0 +1,52 @@
+package taxonomy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNamespaceLevels(t *testing.T) {
+	if got := Parent("cognitive-skill:analysis:deep"); got != "cognitive-skill:analysis" {
+		t.Errorf("Parent = %q", got)
+	}
+	if got := Parent("go"); got != "" {
+		t.Errorf("Parent of top-level tag = %q, expected empty", got)
+	}
+	if got := Leaf("cognitive-skill:analysis"); got != "analysis" {
+		t.Errorf("Leaf = %q", got)
+	}
+	if got := strings.Join(Ancestors("a:b:c"), ","); got != "a,a:b,a:b:c" {
+		t.Errorf("Ancestors = %q", got)
+	}
+}
+
+func TestTagURLRoundTrip(t *testing.T) {
+	tests := []struct {
+		tag string
+		url string
+	}{
+		{"go", "/tag/go"},
+		{"cognitive-skill:analysis", "/tag/cognitive-skill/analysis"},
+		{"bias:sunk cost", "/tag/bias/sunk%20cost"},
+		{"a/b", "/tag/a%2Fb"},
+	}
+
+	for _, test := range tests {
+		if got := TagURL(test.tag); got != test.url {
+			t.Errorf("TagURL(%q) = %q, expected %q", test.tag, got, test.url)
+		}
+		if got, ok := ParseTagPath(test.url); !ok || got != test.tag {
+			t.Errorf("ParseTagPath(%q) = %q, %v, expected %q", test.url, got, ok, test.tag)
+		}
+	}
+
+	// Legacy colon form and a trailing slash parse to the same tag
+	if got, _ := ParseTagPath("/tag/cognitive-skill:analysis/"); got != "cognitive-skill:analysis" {
+		t.Errorf("Legacy path parsed to %q", got)
+	}
+	for _, bad := range []string{"/tag/", "/tags", "/tag/a//b", "/tag/%zz"} {
+		if _, ok := ParseTagPath(bad); ok {
+			t.Errorf("ParseTagPath(%q) should fail", bad)
+		}
+	}
+}
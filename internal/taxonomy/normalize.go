@@ -0,0 +1,65 @@
+package taxonomy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalize returns the key a tag is stored, matched and routed under. Each
+// namespace level is trimmed and case-folded, and runs of anything other than
+// letters, digits and "+", "." or "#" become a single "-", so "Go", " go" and
+// "GO" are one tag and "Sunk Cost" is "sunk-cost". Empty levels are dropped;
+// a tag with no letters or digits normalises to "".
+func Normalize(tag string) string {
+	var levels []string
+	for _, level := range strings.Split(tag, Separator) {
+		if level = slugify(level); level != "" {
+			levels = append(levels, level)
+		}
+	}
+	return strings.Join(levels, Separator)
+}
+
+// DisplayName tidies a tag as written for display: surrounding and repeated
+// whitespace is removed but case and punctuation are kept
+func DisplayName(tag string) string {
+	levels := strings.Split(tag, Separator)
+	kept := levels[:0]
+	for _, level := range levels {
+		if level = strings.Join(strings.Fields(level), " "); level != "" {
+			kept = append(kept, level)
+		}
+	}
+	return strings.Join(kept, Separator)
+}
+
+// slugify normalises a single namespace level
+func slugify(level string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range level {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#':
+			b.WriteRune(foldRune(r))
+			dash = false
+		case r == '.' && b.Len() > 0 && !dash:
+			b.WriteRune(r)
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimRight(b.String(), "-.")
+}
+
+// foldRune maps r to a single representative of its case-folding orbit, so
+// that e.g. the Kelvin sign and "K", or "ſ" and "S", fold like "k" and "s"
+func foldRune(r rune) rune {
+	min := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+	return unicode.ToLower(min)
+}
@@ -0,0 +1,68 @@
+package taxonomy
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{"go", "go"},
+		{"Go", "go"},
+		{"  GO ", "go"},
+		{"Sunk Cost", "sunk-cost"},
+		{"sunk_cost", "sunk-cost"},
+		{"C++", "c++"},
+		{"Node.js", "node.js"},
+		{"C#", "c#"},
+		{"Straße", "straße"},
+		{"\u212aelvin", "kelvin"}, // Kelvin sign folds like K
+		{"Cognitive-Skill : Analysis", "cognitive-skill:analysis"},
+		{"bias::anchoring", "bias:anchoring"},
+		{"--weird--", "weird"},
+		{" ! ", ""},
+	}
+
+	for _, test := range tests {
+		if got := Normalize(test.tag); got != test.expected {
+			t.Errorf("Normalize(%q) = %q, expected %q", test.tag, got, test.expected)
+		}
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{"  Go ", "Go"},
+		{"Sunk   Cost", "Sunk Cost"},
+		{"Cognitive-Skill : Analysis", "Cognitive-Skill:Analysis"},
+	}
+
+	for _, test := range tests {
+		if got := DisplayName(test.tag); got != test.expected {
+			t.Errorf("DisplayName(%q) = %q, expected %q", test.tag, got, test.expected)
+		}
+	}
+}
+
+func TestCanonicalNormalisesAliases(t *testing.T) {
+	tax, err := Parse([]byte("aliases:\n  GoLang: Go\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if got := tax.Canonical(" golang"); got != "go" {
+		t.Errorf("Canonical = %q, expected go", got)
+	}
+
+	tags := []string{"Go", "golang", " go", "Sunk Cost", "!"}
+	if got := tax.CanonicalTags(tags); len(got) != 2 || got[0] != "go" || got[1] != "sunk-cost" {
+		t.Errorf("CanonicalTags = %v", got)
+	}
+	names := tax.DisplayNames(tags)
+	if names["go"] != "Go" || names["sunk-cost"] != "Sunk Cost" || len(names) != 2 {
+		t.Errorf("DisplayNames = %v", names)
+	}
+}
@@ -0,0 +1,277 @@
+package taxonomy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the taxonomy file looked up in the content directory
+const FileName = "taxonomy.yaml"
+
+// DefaultCategory receives tags no category matches, unless the file names another
+const DefaultCategory = "general"
+
+// Taxonomy assigns tags to categories and maps alias tags to canonical ones
+type Taxonomy struct {
+	Categories []*Category       `yaml:"categories"`
+	Default    string            `yaml:"default"` // Category for unmatched tags
+	Aliases    map[string]string `yaml:"aliases"` // Alias tag -> canonical tag
+	byName     map[string]*Category
+}
+
+// Category is a named group of tags. Categories are tried in file order and
+// a tag belongs to the first one whose rules match.
+type Category struct {
+	Name       string `yaml:"name"`       // Identifier, also used in CSS classes
+	Title      string `yaml:"title"`      // Display name; defaults to Name
+	Color      string `yaml:"color"`      // Tag text colour, e.g. "#0066cc"
+	Background string `yaml:"background"` // Tag background colour
+	Match      Match  `yaml:"match"`
+
+	patterns []*regexp.Regexp
+	tags     map[string]bool
+}
+
+// Match lists the rules that place a tag in a category
+type Match struct {
+	Namespace []string `yaml:"namespace"` // Tag is one of these or a descendant (ns:child)
+	Prefix    []string `yaml:"prefix"`    // Tag starts with one of these and continues past it
+	Regex     []string `yaml:"regex"`     // Tag matches one of these expressions
+	Tags      []string `yaml:"tags"`      // Tag is one of these exactly
+}
+
+// colorPattern accepts hex colours and CSS colour names
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{3,8}|[a-zA-Z]+)$`)
+
+// Default returns the built-in taxonomy used when the content directory has
+// no taxonomy file
+func Default() *Taxonomy {
+	t := &Taxonomy{
+		Categories: []*Category{
+			{
+				Name: "cognitive", Title: "Cognitive skills", Color: "#0066cc", Background: "#e6f3ff",
+				Match: Match{Namespace: []string{"cognitive-skill"}},
+			},
+			{
+				Name: "bias", Title: "Bias awareness", Color: "#cc0000", Background: "#ffe6e6",
+				Match: Match{Namespace: []string{"bias"}},
+			},
+			{
+				Name: "technical", Title: "Technical",
+				Match: Match{Tags: []string{
+					"go", "javascript", "typescript", "python", "rust",
+					"react", "htmx", "sqlite", "docker", "rails",
+					"architecture", "backend", "frontend", "api", "database",
+					"performance", "security", "testing", "deployment",
+				}},
+			},
+		},
+	}
+	if err := t.compile(); err != nil {
+		panic(fmt.Sprintf("invalid default taxonomy: %v", err))
+	}
+	return t
+}
+
+// Load reads a taxonomy file. A missing file is not an error: the built-in
+// taxonomy is returned instead.
+func Load(path string) (*Taxonomy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Default(), nil
+		}
+		return nil, fmt.Errorf("failed to read taxonomy: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates taxonomy YAML
+func Parse(data []byte) (*Taxonomy, error) {
+	var t Taxonomy
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse taxonomy: %w", err)
+	}
+	if err := t.compile(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// compile validates the taxonomy and prepares its match rules
+func (t *Taxonomy) compile() error {
+	if t.Default == "" {
+		t.Default = DefaultCategory
+	}
+
+	t.byName = make(map[string]*Category)
+	for i, c := range t.Categories {
+		if c == nil || c.Name == "" {
+			return fmt.Errorf("taxonomy category %d has no name", i+1)
+		}
+		if _, dup := t.byName[c.Name]; dup {
+			return fmt.Errorf("taxonomy category %q is defined twice", c.Name)
+		}
+		if c.Title == "" {
+			c.Title = c.Name
+		}
+		for _, color := range []string{c.Color, c.Background} {
+			if color != "" && !colorPattern.MatchString(color) {
+				return fmt.Errorf("taxonomy category %q: invalid colour %q", c.Name, color)
+			}
+		}
+
+		c.patterns = nil
+		for _, expr := range c.Match.Regex {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("taxonomy category %q: invalid regex %q: %w", c.Name, expr, err)
+			}
+			c.patterns = append(c.patterns, re)
+		}
+		c.tags = make(map[string]bool, len(c.Match.Tags))
+		for _, tag := range c.Match.Tags {
+			c.tags[Normalize(tag)] = true
+		}
+		for i, ns := range c.Match.Namespace {
+			c.Match.Namespace[i] = Normalize(ns)
+		}
+
+		t.byName[c.Name] = c
+	}
+
+	// Unmatched tags need somewhere to go; list the default category last
+	// unless the file placed it explicitly
+	if _, ok := t.byName[t.Default]; !ok {
+		c := &Category{Name: t.Default, Title: titleCase(t.Default), tags: map[string]bool{}}
+		t.Categories = append(t.Categories, c)
+		t.byName[c.Name] = c
+	}
+
+	// Aliases are matched against normalised tags, so normalise both sides
+	aliases := make(map[string]string, len(t.Aliases))
+	for alias, canonical := range t.Aliases {
+		key, target := Normalize(alias), Normalize(canonical)
+		if key == "" || target == "" {
+			return fmt.Errorf("taxonomy alias %q -> %q is empty", alias, canonical)
+		}
+		if key != target {
+			aliases[key] = target
+		}
+	}
+	for alias, canonical := range aliases {
+		if next, chained := aliases[canonical]; chained {
+			return fmt.Errorf("taxonomy alias %q -> %q points at another alias (-> %q)", alias, canonical, next)
+		}
+	}
+	t.Aliases = aliases
+
+	return nil
+}
+
+// matches reports whether tag satisfies any of the category's rules
+func (c *Category) matches(tag string) bool {
+	if c.tags[tag] {
+		return true
+	}
+	for _, ns := range c.Match.Namespace {
+		if tag == ns || strings.HasPrefix(tag, ns+Separator) {
+			return true
+		}
+	}
+	for _, prefix := range c.Match.Prefix {
+		if len(tag) > len(prefix) && strings.HasPrefix(tag, prefix) {
+			return true
+		}
+	}
+	for _, re := range c.patterns {
+		if re.MatchString(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Categorize returns the name of the first category matching tag, or the
+// default category
+func (t *Taxonomy) Categorize(tag string) string {
+	for _, c := range t.Categories {
+		if c.matches(tag) {
+			return c.Name
+		}
+	}
+	return t.Default
+}
+
+// Category returns the named category, or nil
+func (t *Taxonomy) Category(name string) *Category {
+	return t.byName[name]
+}
+
+// Order returns a category's position for sorting; unknown names sort last
+func (t *Taxonomy) Order(name string) int {
+	for i, c := range t.Categories {
+		if c.Name == name {
+			return i
+		}
+	}
+	return len(t.Categories)
+}
+
+// Canonical normalises tag and resolves it if it is an alias
+func (t *Taxonomy) Canonical(tag string) string {
+	tag = Normalize(tag)
+	if canonical, ok := t.Aliases[tag]; ok {
+		return canonical
+	}
+	return tag
+}
+
+// CanonicalTags normalises tags and resolves aliases, dropping empty tags and
+// the duplicates that creates and keeping first-seen order
+func (t *Taxonomy) CanonicalTags(tags []string) []string {
+	if len(tags) == 0 {
+		return tags
+	}
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = t.Canonical(tag)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// DisplayNames maps each canonical tag in tags to the first way it was
+// written. Tags reached through an alias are shown as their canonical key.
+func (t *Taxonomy) DisplayNames(tags []string) map[string]string {
+	names := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		key := t.Canonical(tag)
+		if key == "" || names[key] != "" {
+			continue
+		}
+		if key == Normalize(tag) {
+			names[key] = DisplayName(tag)
+		} else {
+			names[key] = key
+		}
+	}
+	return names
+}
+
+// titleCase upper-cases the first letter of s
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
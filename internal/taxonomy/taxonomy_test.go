@@ -0,0 +1,168 @@
+package taxonomy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultMatchesBuiltInRules(t *testing.T) {
+	tax := Default()
+
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{"cognitive-skill:reflection", "cognitive"},
+		{"cognitive-skill", "cognitive"}, // The namespace's own parent tag
+		{"biased", "general"},            // Namespaces match whole levels only
+		{"bias:anchoring", "bias"},
+		{"go", "technical"},
+		{"sqlite", "technical"},
+		{"meta", "general"},
+	}
+
+	for _, test := range tests {
+		if got := tax.Categorize(test.tag); got != test.expected {
+			t.Errorf("Categorize(%q) = %q, expected %q", test.tag, got, test.expected)
+		}
+	}
+
+	var names []string
+	for _, c := range tax.Categories {
+		names = append(names, c.Name)
+	}
+	if strings.Join(names, ",") != "cognitive,bias,technical,general" {
+		t.Errorf("Unexpected default order %v", names)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tax, err := Parse([]byte(`
+categories:
+  - name: languages
+    title: Programming languages
+    color: "#333"
+    background: "#f0f0f0"
+    match:
+      regex: ["^lang-"]
+      tags: [go, rust]
+  - name: meta
+  - name: psychology
+    match:
+      prefix: ["bias:", "cognitive-skill:"]
+default: meta
+aliases:
+  golang: go
+  js: javascript
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{"go", "languages"},
+		{"lang-zig", "languages"},
+		{"bias:anchoring", "psychology"},
+		{"welcome", "meta"}, // Unmatched tags use the configured default
+	}
+	for _, test := range tests {
+		if got := tax.Categorize(test.tag); got != test.expected {
+			t.Errorf("Categorize(%q) = %q, expected %q", test.tag, got, test.expected)
+		}
+	}
+
+	// The default category keeps the position the file gave it
+	if tax.Order("languages") != 0 || tax.Order("meta") != 1 || tax.Order("psychology") != 2 {
+		t.Error("Expected categories in file order")
+	}
+	if len(tax.Categories) != 3 {
+		t.Errorf("Expected no extra default category, got %d categories", len(tax.Categories))
+	}
+
+	c := tax.Category("languages")
+	if c == nil || c.Title != "Programming languages" || c.Color != "#333" {
+		t.Errorf("Unexpected category %+v", c)
+	}
+	if tax.Category("meta").Title != "meta" {
+		t.Error("Expected title to default to the name")
+	}
+
+	if got := tax.CanonicalTags([]string{"golang", "go", "js", "htmx"}); strings.Join(got, ",") != "go,javascript,htmx" {
+		t.Errorf("Expected aliases resolved and deduplicated, got %v", got)
+	}
+}
+
+func TestParseAppendsDefaultCategory(t *testing.T) {
+	tax, err := Parse([]byte("categories:\n  - name: go\n    match:\n      tags: [go]\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if tax.Categorize("other") != DefaultCategory {
+		t.Errorf("Expected unmatched tags in %s", DefaultCategory)
+	}
+	if tax.Order(DefaultCategory) != 1 {
+		t.Errorf("Expected %s to be listed last", DefaultCategory)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"missing name", "categories:\n  - title: Untitled\n", "has no name"},
+		{"duplicate", "categories:\n  - name: a\n  - name: a\n", "defined twice"},
+		{"bad regex", "categories:\n  - name: a\n    match:\n      regex: [\"(\"]\n", "invalid regex"},
+		{"bad colour", "categories:\n  - name: a\n    color: \"red; display: none\"\n", "invalid colour"},
+		{"chained alias", "aliases:\n  a: b\n  b: c\n", "another alias"},
+		{"bad yaml", "categories: [\n", "failed to parse"},
+	}
+
+	for _, test := range tests {
+		_, err := Parse([]byte(test.yaml))
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.want, err)
+		}
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	tax, err := Load(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatalf("Expected missing file to fall back to the default, got %v", err)
+	}
+	if tax.Categorize("go") != "technical" {
+		t.Error("Expected the built-in taxonomy")
+	}
+
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("categories:\n  - name: only\n    match:\n      tags: [go]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tax, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if tax.Categorize("go") != "only" {
+		t.Error("Expected the file's taxonomy")
+	}
+}
+
+func TestContentTaxonomyFile(t *testing.T) {
+	tax, err := Load(filepath.Join("..", "..", "content", FileName))
+	if err != nil {
+		t.Fatalf("content/%s is invalid: %v", FileName, err)
+	}
+	if tax.Categorize("cognitive-skill:reflection") != "cognitive" {
+		t.Error("Expected cognitive-skill tags in the cognitive category")
+	}
+	if tax.Canonical("golang") != "go" {
+		t.Error("Expected golang to alias go")
+	}
+}
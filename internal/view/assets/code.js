@@ -0,0 +1,34 @@
+// Adds a copy button to each highlighted code block. Line numbers are left
+// out of the copied text: chroma wraps each line's code in span.cl.
+document.addEventListener("DOMContentLoaded", function () {
+  if (!navigator.clipboard) {
+    return;
+  }
+  document.querySelectorAll("pre.chroma").forEach(function (pre) {
+    var wrapper = document.createElement("div");
+    wrapper.className = "code-block";
+    pre.parentNode.insertBefore(wrapper, pre);
+    wrapper.appendChild(pre);
+
+    var button = document.createElement("button");
+    button.type = "button";
+    button.className = "copy-code";
+    button.textContent = "Copy";
+    button.setAttribute("aria-label", "Copy code to clipboard");
+    wrapper.appendChild(button);
+
+    button.addEventListener("click", function () {
+      var lines = pre.querySelectorAll(".cl");
+      var text = lines.length
+        ? Array.prototype.map.call(lines, function (line) { return line.textContent; }).join("")
+        : pre.textContent;
+      navigator.clipboard.writeText(text).then(function () {
+        button.textContent = "Copied";
+      }, function () {
+        button.textContent = "Failed";
+      }).then(function () {
+        setTimeout(function () { button.textContent = "Copy"; }, 2000);
+      });
+    });
+  });
+});
@@ -0,0 +1,15 @@
+// Typesets the TeX the markdown renderer emits for math: true posts:
+// <span class="math math-inline">\(...\)</span> and
+// <div class="math math-display">\[...\]</div>. Loaded after KaTeX.
+document.addEventListener("DOMContentLoaded", function () {
+  if (typeof katex === "undefined") {
+    return; // Leave the TeX source readable
+  }
+  document.querySelectorAll(".math").forEach(function (el) {
+    var tex = el.textContent.trim().replace(/^\\[(\[]/, "").replace(/\\[)\]]$/, "");
+    katex.render(tex, el, {
+      displayMode: el.classList.contains("math-display"),
+      throwOnError: false
+    });
+  });
+});
@@ -0,0 +1,17 @@
+// Draws the Mermaid diagrams the markdown renderer emits as
+// <pre class="mermaid">, using the vendored library loaded just before this
+// script. Loaded only on posts that have one; without it the diagram source
+// stays readable.
+(function () {
+  if (!window.mermaid) {
+    console.error("mermaid.js: /static/vendor/mermaid/mermaid.min.js did not load; run scripts/vendor-assets.sh");
+    return;
+  }
+
+  mermaid.initialize({
+    startOnLoad: false,
+    securityLevel: "strict",
+    theme: window.matchMedia("(prefers-color-scheme: dark)").matches ? "dark" : "default"
+  });
+  mermaid.run({ querySelector: "pre.mermaid" });
+})();
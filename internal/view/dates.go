@@ -0,0 +1,97 @@
+package view
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultDateLayout is how formatDate prints dates without an explicit layout
+const defaultDateLayout = "January 2, 2006"
+
+// toTime converts a template value to a time. Strings are parsed as RFC3339,
+// the format posts store their dates in; anything unparseable is reported as
+// not ok so templates can fall back to printing the raw value.
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, !t.IsZero()
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, false
+		}
+		return *t, !t.IsZero()
+	case string:
+		parsed, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return parsed, true
+	}
+	return time.Time{}, false
+}
+
+// formatDate prints v in loc using layout, or "January 2, 2006" by default
+func formatDate(v interface{}, loc *time.Location, layout ...string) string {
+	t, ok := toTime(v)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	l := defaultDateLayout
+	if len(layout) > 0 && layout[0] != "" {
+		l = layout[0]
+	}
+	return t.In(loc).Format(l)
+}
+
+// isoDate prints v as RFC3339 in loc, for <time datetime="...">
+func isoDate(v interface{}, loc *time.Location) string {
+	t, ok := toTime(v)
+	if !ok {
+		return ""
+	}
+	return t.In(loc).Format(time.RFC3339)
+}
+
+// relativeTime describes v relative to now, e.g. "3 days ago" or "in 2 hours".
+// Anything more than a year away falls back to the full date in loc.
+func relativeTime(v interface{}, now time.Time, loc *time.Location) string {
+	t, ok := toTime(v)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var amount string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		amount = plural(int(d/time.Minute), "minute")
+	case d < 24*time.Hour:
+		amount = plural(int(d/time.Hour), "hour")
+	case d < 30*24*time.Hour:
+		amount = plural(int(d/(24*time.Hour)), "day")
+	case d < 365*24*time.Hour:
+		amount = plural(int(d/(30*24*time.Hour)), "month")
+	default:
+		return t.In(loc).Format(defaultDateLayout)
+	}
+
+	if future {
+		return "in " + amount
+	}
+	return amount + " ago"
+}
+
+// plural formats n with unit, pluralising unit when n != 1
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,53 @@
+package view
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDate(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("zone data unavailable: %v", err)
+	}
+
+	// Midnight in LA is stored as 07:00 UTC and must display on the same day
+	if got := formatDate("2025-09-17T07:00:00Z", la); got != "September 17, 2025" {
+		t.Errorf("Expected local date, got %q", got)
+	}
+	if got := formatDate("2025-09-17T07:00:00Z", time.UTC, "2006-01-02 15:04"); got != "2025-09-17 07:00" {
+		t.Errorf("Expected custom layout, got %q", got)
+	}
+	if got := formatDate("not a date", time.UTC); got != "not a date" {
+		t.Errorf("Expected raw value for unparseable input, got %q", got)
+	}
+	if got := isoDate("2025-09-17T07:00:00Z", la); got != "2025-09-17T00:00:00-07:00" {
+		t.Errorf("Expected ISO date with zone offset, got %q", got)
+	}
+	if got := isoDate("", la); got != "" {
+		t.Errorf("Expected empty ISO date for empty input, got %q", got)
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2025, 9, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		at       time.Time
+		expected string
+	}{
+		{now.Add(-30 * time.Second), "just now"},
+		{now.Add(-1 * time.Minute), "1 minute ago"},
+		{now.Add(-5 * time.Hour), "5 hours ago"},
+		{now.Add(-3 * 24 * time.Hour), "3 days ago"},
+		{now.Add(-65 * 24 * time.Hour), "2 months ago"},
+		{now.Add(2 * time.Hour), "in 2 hours"},
+		{now.Add(-400 * 24 * time.Hour), "August 13, 2024"},
+	}
+
+	for _, test := range tests {
+		if got := relativeTime(test.at.Format(time.RFC3339), now, time.UTC); got != test.expected {
+			t.Errorf("relativeTime(%s) = %q, expected %q", test.at, got, test.expected)
+		}
+	}
+}
@@ -6,24 +6,46 @@ import (
     "bytes"
     "os"
     "path/filepath"
+    "time"
+
+    "notebook.oceanheart.ai/internal/taxonomy"
 )
 
 // Manager loads and executes file-based templates.
 type Manager struct {
     dir   string
     dev   bool
+    loc   *time.Location
     tmpl  *template.Template
 }
 
 // NewManager creates a new template manager rooted at dir.
 func NewManager(dir string, dev bool) *Manager {
-    return &Manager{dir: dir, dev: dev}
+    return &Manager{dir: dir, dev: dev, loc: time.UTC}
+}
+
+// SetLocation sets the time zone dates are displayed in.
+func (m *Manager) SetLocation(loc *time.Location) {
+    if loc == nil {
+        loc = time.UTC
+    }
+    m.loc = loc
 }
 
 // funcs returns the template function map.
 func (m *Manager) funcs() template.FuncMap {
     return template.FuncMap{
         "safeHTML": func(s string) template.HTML { return template.HTML(s) },
+        "formatDate": func(v interface{}, layout ...string) string {
+            return formatDate(v, m.loc, layout...)
+        },
+        "relativeTime": func(v interface{}) string {
+            return relativeTime(v, time.Now(), m.loc)
+        },
+        "isoDate": func(v interface{}) string {
+            return isoDate(v, m.loc)
+        },
+        "tagURL": taxonomy.TagURL,
     }
 }
 
@@ -83,6 +105,16 @@ func (m *Manager) Execute(w io.Writer, name string, data interface{}) error {
     return m.tmpl.ExecuteTemplate(w, name, data)
 }
 
+// Has reports whether a template with the given name is defined.
+func (m *Manager) Has(name string) bool {
+    if m.dev || m.tmpl == nil {
+        if err := m.parse(); err != nil {
+            return false
+        }
+    }
+    return m.tmpl.Lookup(name) != nil
+}
+
 // RenderString renders a named template to a string (useful for partials/pages).
 func (m *Manager) RenderString(name string, data interface{}) (string, error) {
     if m.dev || m.tmpl == nil {
@@ -0,0 +1,11 @@
+-- Content sections mapped from top-level content directories
+ALTER TABLE posts ADD COLUMN section TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE sections (
+  name TEXT PRIMARY KEY,
+  title TEXT NOT NULL,
+  summary TEXT NOT NULL DEFAULT '',
+  intro_html TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_posts_section ON posts(section, published_at DESC);
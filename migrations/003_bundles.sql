@@ -0,0 +1,2 @@
+-- Page bundles: posts stored as a directory with co-located assets
+ALTER TABLE posts ADD COLUMN bundle_dir TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- Table of contents: heading tree as JSON plus the toc: front matter flag
+ALTER TABLE posts ADD COLUMN toc TEXT NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN show_toc BOOLEAN NOT NULL DEFAULT 0;
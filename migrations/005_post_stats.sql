@@ -0,0 +1,4 @@
+-- Word count, reading time and auto-excerpt computed at load time
+ALTER TABLE posts ADD COLUMN word_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN reading_time INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN excerpt TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+-- Multi-part series from series: and series_order: front matter
+ALTER TABLE posts ADD COLUMN series TEXT NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN series_order INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_posts_series ON posts(series, series_order);
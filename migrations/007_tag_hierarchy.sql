@@ -0,0 +1,4 @@
+-- Namespaced tags: "a:b" is the child of "a"; parents are created on upsert
+ALTER TABLE tags ADD COLUMN parent_id INTEGER REFERENCES tags(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tags_parent ON tags(parent_id);
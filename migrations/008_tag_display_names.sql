@@ -0,0 +1,5 @@
+-- Tags are stored normalised (name) with the form they were written in
+-- (display_name); mergeDuplicateTags then normalises existing names
+ALTER TABLE tags ADD COLUMN display_name TEXT NOT NULL DEFAULT '';
+
+UPDATE tags SET display_name = name;
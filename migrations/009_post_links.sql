@@ -0,0 +1,9 @@
+-- Links between posts by target slug, so a link can point at a post that
+-- is loaded later in the same batch; backlinks are read by target_slug
+CREATE TABLE post_links (
+  source_id   INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+  target_slug TEXT NOT NULL,
+  PRIMARY KEY (source_id, target_slug)
+);
+
+CREATE INDEX idx_post_links_target ON post_links(target_slug);
@@ -0,0 +1,2 @@
+-- math: front matter; pages load the math assets only for these posts
+ALTER TABLE posts ADD COLUMN math BOOLEAN NOT NULL DEFAULT 0;
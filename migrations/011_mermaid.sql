@@ -0,0 +1,3 @@
+-- Set at render time for posts with a diagram drawn by mermaid.js, so
+-- pages load it only for these posts
+ALTER TABLE posts ADD COLUMN mermaid BOOLEAN NOT NULL DEFAULT 0;
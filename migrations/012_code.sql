@@ -0,0 +1,3 @@
+-- Set at render time for posts with highlighted code blocks, so pages
+-- load the copy buttons only for these posts
+ALTER TABLE posts ADD COLUMN code BOOLEAN NOT NULL DEFAULT 0;
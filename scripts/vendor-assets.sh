@@ -0,0 +1,104 @@
+#!/usr/bin/env bash
+set -euo pipefail
+
+# Vendors the third-party browser libraries served under /static/vendor:
+# KaTeX (math: true posts) and Mermaid (```mermaid diagrams).
+#
+# The vendored files and internal/view/assets/vendor/SHA256SUMS are committed
+# together. Usage:
+#
+#   scripts/vendor-assets.sh            re-fetch and install the pinned files,
+#                                       which must match SHA256SUMS
+#   scripts/vendor-assets.sh --update   re-fetch after bumping a version and
+#                                       rewrite SHA256SUMS for review in git
+#   scripts/vendor-assets.sh --check    verify the committed files against
+#                                       SHA256SUMS without fetching (used by
+#                                       the Docker build)
+#
+# Packages are fetched from the npm registry at the pinned versions and
+# checked against the registry's integrity hash before anything is copied.
+# A missing SHA256SUMS is an error unless --update is given.
+#
+# Requirements: curl, tar, sha256sum, openssl
+
+KATEX_VERSION=0.16.11
+MERMAID_VERSION=11.4.1
+
+ROOT="$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)"
+VENDOR="${ROOT}/internal/view/assets/vendor"
+SUMS="${VENDOR}/SHA256SUMS"
+REGISTRY="https://registry.npmjs.org"
+
+MODE="${1:-}"
+case "${MODE}" in
+  ""|--update|--check) ;;
+  *)
+    echo "usage: $0 [--update|--check]" >&2
+    exit 2
+    ;;
+esac
+
+if [[ "${MODE}" != "--update" && ! -f "${SUMS}" ]]; then
+  echo "ERROR: ${SUMS} is missing; run $0 --update and commit the result" >&2
+  exit 1
+fi
+
+if [[ "${MODE}" == "--check" ]]; then
+  cd "${VENDOR}"
+  sha256sum --quiet --strict -c "${SUMS}"
+  echo "Vendored files match ${SUMS}"
+  exit 0
+fi
+
+TMP="$(mktemp -d)"
+trap 'rm -rf "${TMP}"' EXIT
+
+# fetch NAME VERSION downloads and verifies a package tarball into $TMP/NAME
+fetch() {
+  local name=$1 version=$2
+  local tarball="${TMP}/${name}.tgz"
+
+  echo "Fetching ${name}@${version}"
+  local integrity
+  integrity=$(curl -fsSL "${REGISTRY}/${name}/${version}" | sed -n 's/.*"integrity":"\(sha512-[^"]*\)".*/\1/p')
+  if [[ -z "${integrity}" ]]; then
+    echo "ERROR: no integrity hash for ${name}@${version}" >&2
+    exit 1
+  fi
+  curl -fsSL -o "${tarball}" "${REGISTRY}/${name}/-/${name}-${version}.tgz"
+  local actual="sha512-$(openssl dgst -sha512 -binary "${tarball}" | openssl base64 -A)"
+  if [[ "${actual}" != "${integrity}" ]]; then
+    echo "ERROR: ${name}@${version} does not match the registry integrity hash" >&2
+    exit 1
+  fi
+
+  mkdir -p "${TMP}/${name}"
+  tar -xzf "${tarball}" -C "${TMP}/${name}"
+}
+
+fetch katex "${KATEX_VERSION}"
+fetch mermaid "${MERMAID_VERSION}"
+
+# Stage the files, then check them before touching the tree
+STAGE="${TMP}/vendor"
+mkdir -p "${STAGE}/katex/fonts" "${STAGE}/mermaid"
+cp "${TMP}/katex/package/dist/katex.min.js" "${TMP}/katex/package/dist/katex.min.css" "${STAGE}/katex/"
+cp "${TMP}/katex/package/dist/fonts/"*.woff2 "${STAGE}/katex/fonts/"
+cp "${TMP}/katex/package/LICENSE" "${STAGE}/katex/"
+cp "${TMP}/mermaid/package/dist/mermaid.min.js" "${STAGE}/mermaid/"
+cp "${TMP}/mermaid/package/LICENSE" "${STAGE}/mermaid/"
+
+cd "${STAGE}"
+find katex mermaid -type f | LC_ALL=C sort | xargs sha256sum > SHA256SUMS
+if [[ "${MODE}" == "--update" ]]; then
+  echo "Rewriting ${SUMS}; review and commit it with the vendored files"
+elif ! cmp -s SHA256SUMS "${SUMS}"; then
+  diff -u "${SUMS}" SHA256SUMS >&2 || true
+  echo "ERROR: fetched files do not match ${SUMS}" >&2
+  exit 1
+fi
+
+rm -rf "${VENDOR}/katex" "${VENDOR}/mermaid"
+mkdir -p "${VENDOR}"
+cp -R katex mermaid SHA256SUMS "${VENDOR}/"
+echo "Vendored KaTeX ${KATEX_VERSION} and Mermaid ${MERMAID_VERSION} into ${VENDOR}"